@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/kappere/go-rest/core/metric"
 	"github.com/kappere/go-rest/core/tool/common"
 )
 
@@ -24,6 +25,11 @@ var (
 	cacheQueue = &common.PriorityQueue{}
 )
 
+var (
+	cacheHits   = metric.NewCounterVec("cache_hits_total", "Number of cache hits.")
+	cacheMisses = metric.NewCounterVec("cache_misses_total", "Number of cache misses.")
+)
+
 func CachingPerm[V any](key string, defaultValueF func() V) V {
 	return Caching(key, defaultValueF, time.Hour*24*365*99)
 }
@@ -31,8 +37,10 @@ func CachingPerm[V any](key string, defaultValueF func() V) V {
 func Caching[V any](key string, defaultValueF func() V, d time.Duration) V {
 	now := time.Now()
 	if c, ok := cacheMap[key]; ok && c.expire.After(now) {
+		cacheHits.Inc()
 		return c.value.(V)
 	}
+	cacheMisses.Inc()
 	value := defaultValueF()
 	c := cacheItem{
 		key,
@@ -14,6 +14,7 @@ type BaseConfig struct {
 	Log      conf.LogConfig
 	Database conf.DatabaseConfig
 	Redis    conf.RedisConfig
+	Trace    conf.TraceConfig
 }
 
 var DefaultBaseConfig = BaseConfig{
@@ -23,7 +24,9 @@ var DefaultBaseConfig = BaseConfig{
 		Debug:   false,
 	},
 	Http: conf.HttpConfig{
-		Port: 80,
+		Port:            80,
+		ShutdownTimeout: 8000,
+		ShutdownDelay:   5000, // 不小于k8s模板中的就绪检查周期
 		Session: conf.SessionConfig{
 			Name:      "sessionid",
 			Domain:    "",
@@ -45,6 +48,21 @@ var DefaultBaseConfig = BaseConfig{
 			Expire:   7200,
 			TokenUri: "/token",
 		},
+		Metrics: conf.MetricsConfig{
+			Enable: true,
+			Path:   "/metrics",
+		},
+		OpenApi: conf.OpenApiConfig{
+			Enable:        true,
+			Path:          "/openapi.json",
+			SwaggerPath:   "/swagger",
+			SwaggerAssets: "https://unpkg.com/swagger-ui-dist@5",
+		},
+		Admin: conf.AdminConfig{
+			Enable: false,
+			Host:   "127.0.0.1",
+			Port:   6060,
+		},
 		Rpc: conf.RpcConfig{
 			Token: strconv.Itoa(rand.Int()),
 			// Kubernetes IpProxy
@@ -64,8 +82,20 @@ var DefaultBaseConfig = BaseConfig{
 		},
 	},
 	Log: conf.LogConfig{
-		Path: "log",
+		Path:    "log",
+		MaxSize: 100,
+		MaxAge:  7,
+		Async: conf.AsyncLogConfig{
+			Enable:        false,
+			QueueSize:     8192,
+			FlushInterval: 1000,
+		},
 	},
 	Database: conf.DatabaseConfig{},
 	Redis:    conf.RedisConfig{},
+	Trace: conf.TraceConfig{
+		Enable:      false,
+		Exporter:    "stdout",
+		SampleRatio: 1,
+	},
 }
@@ -10,16 +10,27 @@ type HttpConfig struct {
 	MaxConns int
 	MaxBytes int64
 	// milliseconds
-	Timeout      int64
-	CpuThreshold int64
+	Timeout int64
+	// ShutdownTimeout 优雅关闭超时，milliseconds
+	ShutdownTimeout int64
+	// ShutdownDelay 关闭时/readyz返回503后等待负载均衡摘除流量的时间，milliseconds
+	ShutdownDelay int64
+	CpuThreshold  int64
+	// HttpStatus 错误响应使用状态码注册的HTTP状态码（如-999为401），默认始终返回200并以code表示错误
+	HttpStatus bool
 	// TraceIgnorePaths is paths blacklist for trace middleware.
 	TraceIgnorePaths []string
+	AccessLog        AccessLogConfig
+	Recovery         RecoveryConfig
 
 	Session        SessionConfig
 	PeriodLimit    PeriodLimitConfig
 	OAuth2         OAuth2Config
 	StaticResource StaticResourceConfig
 	Rpc            RpcConfig
+	Metrics        MetricsConfig
+	Admin          AdminConfig
+	OpenApi        OpenApiConfig
 }
 
 type SessionConfig struct {
@@ -63,6 +74,64 @@ type KubernetesConfig struct {
 	PortName  string
 }
 
+type MetricsConfig struct {
+	Enable bool
+	Path   string
+}
+
+// OpenApiConfig 由rest.Route注册的路由生成OpenAPI 3文档
+type OpenApiConfig struct {
+	Enable bool
+	// Path 文档地址，默认/openapi.json
+	Path string
+	// SwaggerPath Swagger UI地址，仅app.debug为true时提供，默认/swagger
+	SwaggerPath string
+	// SwaggerAssets Swagger UI静态资源swagger-ui.css和swagger-ui-bundle.js所在的地址，
+	// 无法访问外网时可指向内网镜像或http.static下的目录，默认https://unpkg.com/swagger-ui-dist@5
+	SwaggerAssets string
+	// Version 文档中的接口版本，默认取构建信息中的模块版本
+	Version string
+}
+
+// AdminConfig 管理端口，提供pprof、运行时统计等诊断接口，与业务端口分开
+type AdminConfig struct {
+	Enable bool
+	// Host 默认只监听127.0.0.1，可通过kubectl port-forward访问
+	Host string
+	Port int
+	// Username 非空时使用basic auth，否则使用rpc token鉴权
+	Username string
+	Password string
+}
+
+// AccessLogConfig 访问日志，路径均支持glob，如/static/*，以/**结尾时匹配该前缀下的所有路径
+type AccessLogConfig struct {
+	// SkipPaths 不记录的路径，与TraceIgnorePaths合并
+	SkipPaths []string
+	// Sample 按路径采样比例[0, 1]，如/api/ping: 0.01，未配置的路径全部记录，5xx和慢请求始终记录
+	Sample map[string]float64
+	// SlowThreshold 慢请求阈值，超过时以WARN输出并附带请求头，milliseconds，0表示关闭
+	SlowThreshold int64
+	// BodyPaths 记录请求和响应体的路径
+	BodyPaths []string
+	// BodyLimit 记录的最大字节数，默认4096
+	BodyLimit int
+	// RedactFields 脱敏字段名，不区分大小写，默认password、token、id_card等
+	RedactFields []string
+}
+
+// RecoveryConfig panic恢复和上报
+type RecoveryConfig struct {
+	// Message 返回给用户的提示，默认"服务器异常，请联系管理员"
+	Message string
+	// HttpStatus 返回HTTP 500，默认返回200并以code表示错误
+	HttpStatus bool
+	// Webhook 非空时将panic的请求和堆栈POST到该地址
+	Webhook string
+	// WebhookHeaders 格式为key=value
+	WebhookHeaders []string
+}
+
 type StaticResourceConfig struct {
 	Location string
 	Fs       interface{}
@@ -2,4 +2,32 @@ package conf
 
 type LogConfig struct {
 	Path string
+	// 日志级别：debug/info/warn/error，默认info
+	Level string
+	// Format 输出格式：text/json，默认text
+	Format string
+	// Modules 模块日志级别，覆盖全局级别，如rest: warn、rpc: debug、db: warn、task: info
+	Modules map[string]string
+	// Stdout 只输出到标准输出，不写日志文件，适用于容器环境
+	Stdout bool
+	// MaxSize 单个日志文件最大MB，超过后切分，0表示只按天切分
+	MaxSize int
+	// MaxAge 历史日志保留天数，0表示不按时间清理
+	MaxAge int
+	// MaxBackups 历史日志保留个数，0表示不按个数清理
+	MaxBackups int
+	// Compress 历史日志gzip压缩
+	Compress bool
+	// Async 异步写入，避免请求路径上同步写磁盘
+	Async AsyncLogConfig
+}
+
+type AsyncLogConfig struct {
+	Enable bool
+	// QueueSize 队列行数，默认8192
+	QueueSize int
+	// Block 队列满时阻塞写入方，默认丢弃并计入log_dropped_lines_total
+	Block bool
+	// FlushInterval 刷新间隔，单位毫秒，默认1000
+	FlushInterval int64
 }
@@ -0,0 +1,15 @@
+package conf
+
+type TraceConfig struct {
+	Enable bool
+	// Exporter 导出方式：otlp/stdout/file，默认stdout
+	Exporter string
+	// Endpoint otlp collector地址，如http://127.0.0.1:4318
+	Endpoint string
+	// Headers otlp请求头，格式为key=value，如鉴权信息
+	Headers []string
+	// File file导出的文件路径
+	File string
+	// SampleRatio 新trace的采样比例[0, 1]，上游已采样的trace沿用上游结果
+	SampleRatio float64
+}
@@ -2,25 +2,343 @@ package config
 
 import (
 	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-func Load(path string, v any) error {
-	data, err := os.ReadFile(path)
+// PROFILE_ENV 指定profile的环境变量，优先级高于配置文件中的app.profile
+const PROFILE_ENV = "PROFILE"
+
+// Load 加载配置文件，并叠加同目录下的<name>-<profile>.yaml。
+// 配置文件严格解码，未知配置项和类型错误带行号报告；加载完成后校验BaseConfig，所有错误合并返回。
+//
+// 配置优先级（由低到高，后者覆盖前者）：
+//  1. v中已有的默认值，如DefaultBaseConfig
+//  2. 基础配置文件<name>.yaml
+//  3. profile配置文件<name>-<profile>.yaml，profile取自环境变量PROFILE或app.profile
+//  4. 环境变量APP_<PATH>，如APP_HTTP_PORT、APP_REDIS_ADDR
+//  5. 命令行覆盖项overrides，格式为key=value，如http.port=8080
+//
+// 配置文件中的值支持${ENV_NAME:default}占位符，在解析文件时替换。
+// 值为ENC(...)时使用主密钥解密（见CONFIG_KEY），环境变量和命令行覆盖项同样支持。
+func Load(path string, v any, overrides ...string) error {
+	detachValue(v)
+	if err := loadWithProfile(os.ReadFile, path, v); err != nil {
+		return err
+	}
+	return applyExternal(v, overrides)
+}
+
+// LoadEmbed 从嵌入文件系统加载配置，基础配置文件为<name>.yaml（*.example.yaml除外），
+// 再叠加<name>-<profile>.yaml，优先级同Load
+func LoadEmbed(configFs embed.FS, v any, overrides ...string) error {
+	detachValue(v)
+	basePath, err := findBaseFile(configFs)
 	if err != nil {
 		return err
 	}
-	loadFromBytes(data, v)
-	return nil
+	if err := loadWithProfile(configFs.ReadFile, basePath, v); err != nil {
+		return err
+	}
+	return applyExternal(v, overrides)
 }
 
-func LoadEmbed(configFs embed.FS, v any) error {
+// applyExternal 依次应用环境变量和命令行覆盖项，最后校验BaseConfig
+func applyExternal(v any, overrides []string) error {
+	if err := applyEnv(v); err != nil {
+		return err
+	}
+	if err := applyOverrides(v, overrides); err != nil {
+		return err
+	}
+	if bc := baseConfigOf(v); bc != nil {
+		return bc.Validate()
+	}
 	return nil
 }
 
-func loadFromBytes(data []byte, v any) error {
-	yaml.Unmarshal(data, v)
+// detachValue 将v中的map和slice替换为副本再解码。v通常复制自DefaultBaseConfig，
+// 与其共享map，yaml解码到共享的map会修改默认值，且Watcher重新加载时新旧配置为同一个map
+func detachValue(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		detach(rv.Elem())
+	}
+}
+
+func detach(rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Struct:
+		for i := 0; i < rv.NumField(); i++ {
+			if rv.Type().Field(i).IsExported() {
+				detach(rv.Field(i))
+			}
+		}
+	case reflect.Map:
+		if rv.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			value := reflect.New(rv.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			detach(value)
+			m.SetMapIndex(iter.Key(), value)
+		}
+		rv.Set(m)
+	case reflect.Slice:
+		if rv.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		reflect.Copy(s, rv)
+		for i := 0; i < s.Len(); i++ {
+			detach(s.Index(i))
+		}
+		rv.Set(s)
+	}
+}
+
+// loadWithProfile 先加载基础配置，再按profile叠加覆盖配置。
+// yaml解码到已有值时只覆盖出现的字段，嵌套结构体和map因此按层级合并。
+// 两个文件中的解析错误会合并返回。
+func loadWithProfile(readFile func(string) ([]byte, error), basePath string, v any) error {
+	data, err := readFile(basePath)
+	if err != nil {
+		return err
+	}
+	baseErr := loadFromBytes(basePath, data, v)
+	profile := resolveProfile(v)
+	if profile == "" {
+		return baseErr
+	}
+	ext := path.Ext(basePath)
+	profilePath := strings.TrimSuffix(basePath, ext) + "-" + profile + ext
+	data, err = readFile(profilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return baseErr
+	}
+	if err != nil {
+		return errors.Join(baseErr, err)
+	}
+	return errors.Join(baseErr, loadFromBytes(profilePath, data, v))
+}
+
+// loadFromBytes 严格解码：未知配置项和类型错误均带行号报告
+func loadFromBytes(name string, data []byte, v any) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	// 空文件
+	if node.Kind == 0 {
+		return nil
+	}
+	return decodeNode(name, &node, v)
+}
+
+// decodeNode 替换占位符、解密后严格解码到v，所有配置源共用
+func decodeNode(name string, node *yaml.Node, v any) error {
+	interpolateNode(node)
+	var errs []error
+	for _, e := range (&secretResolver{}).decryptNode(node, "") {
+		errs = append(errs, fmt.Errorf("%s: %s", name, e))
+	}
+	resolveDotenvNode(node, reflect.TypeOf(v))
+	for _, e := range checkKnownFields(node, reflect.TypeOf(v), "") {
+		errs = append(errs, fmt.Errorf("%s: %s", name, e))
+	}
+	if err := node.Decode(v); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			for _, e := range typeErr.Errors {
+				errs = append(errs, fmt.Errorf("%s: %s", name, e))
+			}
+		} else {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkKnownFields 检查yaml中不存在于t的键，map和interface{}接受任意键
+func checkKnownFields(n *yaml.Node, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if n.Kind == yaml.DocumentNode || n.Kind == yaml.AliasNode {
+		if n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		var errs []string
+		for _, c := range n.Content {
+			errs = append(errs, checkKnownFields(c, t, prefix)...)
+		}
+		return errs
+	}
+	var errs []string
+	switch {
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Type)
+		collectFields(t, fields)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			ft, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("line %d: unknown config key %q", key.Line, prefix+key.Value))
+				continue
+			}
+			errs = append(errs, checkKnownFields(value, ft, prefix+key.Value+".")...)
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			errs = append(errs, checkKnownFields(n.Content[i+1], t.Elem(), prefix+n.Content[i].Value+".")...)
+		}
+	case n.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for _, c := range n.Content {
+			errs = append(errs, checkKnownFields(c, t.Elem(), prefix)...)
+		}
+	}
+	return errs
+}
+
+// collectFields 收集结构体的yaml键名，inline字段展开到当前层级
+func collectFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, inline := yamlFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		if inline && sf.Type.Kind() == reflect.Struct {
+			collectFields(sf.Type, fields)
+			continue
+		}
+		fields[name] = sf.Type
+	}
+}
+
+// resolveProfile 环境变量PROFILE优先，其次为配置中的app.profile，并回写到配置中
+func resolveProfile(v any) string {
+	bc := baseConfigOf(v)
+	profile := os.Getenv(PROFILE_ENV)
+	if bc == nil {
+		return profile
+	}
+	if profile != "" {
+		bc.App.Profile = profile
+	}
+	return bc.App.Profile
+}
+
+// baseConfigOf 查找v中的BaseConfig，v可以是*BaseConfig或嵌入了BaseConfig的结构体指针
+func baseConfigOf(v any) *BaseConfig {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil
+	}
+	return findBaseConfig(rv.Elem())
+}
+
+func findBaseConfig(rv reflect.Value) *BaseConfig {
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	if bc, ok := rv.Addr().Interface().(*BaseConfig); ok {
+		return bc
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Field(i)
+		if !field.CanAddr() || !rv.Type().Field(i).IsExported() {
+			continue
+		}
+		if bc := findBaseConfig(field); bc != nil {
+			return bc
+		}
+	}
 	return nil
 }
+
+// findBaseFile 在嵌入文件系统中查找唯一的基础配置文件
+func findBaseFile(configFs fs.FS) (string, error) {
+	var files []string
+	err := fs.WalkDir(configFs, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isConfigFile(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return selectBaseFile(files, "embed fs")
+}
+
+// findBaseFileInDir 在目录中查找唯一的基础配置文件，只读取顶层文件并忽略.开头的文件。
+// 挂载的ConfigMap中真实文件位于..<timestamp>/子目录，顶层为指向它的软链接
+func findBaseFileInDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") && isConfigFile(e.Name()) {
+			files = append(files, e.Name())
+		}
+	}
+	return selectBaseFile(files, "directory "+dir)
+}
+
+// isConfigFile yaml配置文件，*.example.yaml除外
+func isConfigFile(p string) bool {
+	ext := path.Ext(p)
+	return (ext == ".yaml" || ext == ".yml") && !strings.HasSuffix(strings.TrimSuffix(p, ext), ".example")
+}
+
+// selectBaseFile 从配置文件中排除profile覆盖文件，要求只剩一个基础配置文件
+func selectBaseFile(files []string, location string) (string, error) {
+	sort.Strings(files)
+	var bases []string
+	for _, f := range files {
+		if !isProfileFile(f, files) {
+			bases = append(bases, f)
+		}
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("no config file found in %s", location)
+	}
+	if len(bases) > 1 {
+		return "", fmt.Errorf("ambiguous base config files in %s: %s", location, strings.Join(bases, ", "))
+	}
+	return bases[0], nil
+}
+
+// isProfileFile 判断f是否为files中某个基础配置的profile覆盖文件，即<name>-<profile>.yaml
+func isProfileFile(f string, files []string) bool {
+	stem := strings.TrimSuffix(f, path.Ext(f))
+	for _, base := range files {
+		if base == f {
+			continue
+		}
+		if strings.HasPrefix(stem, strings.TrimSuffix(base, path.Ext(base))+"-") {
+			return true
+		}
+	}
+	return false
+}
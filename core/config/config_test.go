@@ -0,0 +1,278 @@
+package config
+
+import (
+	"embed"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kappere/go-rest/core/config/conf"
+)
+
+//go:embed testdata/embed
+var testEmbedFs embed.FS
+
+func newTestBaseConfig() BaseConfig {
+	c := DefaultBaseConfig
+	c.Http.Rpc.IpProxy = conf.IpProxyConfig{}
+	c.Http.Rpc.Kubernetes = conf.KubernetesConfig{}
+	return c
+}
+
+func TestLoadEmbed(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   string
+		port      int
+		storeType string
+		proxy     map[string]string
+	}{
+		{
+			name:      "profile from file",
+			profile:   "",
+			port:      8080,
+			storeType: "cookie",
+			proxy: map[string]string{
+				"*":     "http://127.0.0.1:8080",
+				"user":  "http://127.0.0.1:8081",
+				"order": "http://127.0.0.1:8082",
+			},
+		},
+		{
+			name:      "profile from env",
+			profile:   "prod",
+			port:      80,
+			storeType: "memory",
+			proxy: map[string]string{
+				"*":    "http://127.0.0.1:8080",
+				"user": "http://127.0.0.1:8081",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PROFILE_ENV, tt.profile)
+			c := struct {
+				BaseConfig `yaml:",inline"`
+			}{newTestBaseConfig()}
+			if err := LoadEmbed(testEmbedFs, &c); err != nil {
+				t.Fatalf("LoadEmbed() error = %v", err)
+			}
+			if c.Http.Port != tt.port {
+				t.Errorf("port = %d, want %d", c.Http.Port, tt.port)
+			}
+			if c.Http.Session.StoreType != tt.storeType {
+				t.Errorf("storetype = %s, want %s", c.Http.Session.StoreType, tt.storeType)
+			}
+			if c.Http.Session.Name != "base_session" {
+				t.Errorf("session name = %s, want base_session", c.Http.Session.Name)
+			}
+			if len(c.Http.Rpc.IpProxy.Proxy) != len(tt.proxy) {
+				t.Errorf("proxy = %v, want %v", c.Http.Rpc.IpProxy.Proxy, tt.proxy)
+			}
+			for k, v := range tt.proxy {
+				if c.Http.Rpc.IpProxy.Proxy[k] != v {
+					t.Errorf("proxy[%s] = %s, want %s", k, c.Http.Rpc.IpProxy.Proxy[k], v)
+				}
+			}
+			if tt.profile != "" && c.App.Profile != tt.profile {
+				t.Errorf("profile = %s, want %s", c.App.Profile, tt.profile)
+			}
+		})
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.yaml"), `
+app:
+  profile: dev
+http:
+  port: ${TEST_HTTP_PORT:8080}
+  traceignorepaths: [/ping]
+database:
+  dsn: "${TEST_DSN}"
+redis:
+  addr: ${TEST_REDIS_HOST:127.0.0.1}:6379
+  password: ${TEST_REDIS_PASSWORD:secret}
+`)
+	writeFile(t, filepath.Join(dir, "app-dev.yaml"), `
+http:
+  port: 8081
+redis:
+  password: dev_secret
+`)
+	tests := []struct {
+		name      string
+		env       map[string]string
+		overrides []string
+		port      int
+		dsn       string
+		addr      string
+		password  string
+	}{
+		{
+			name:     "file and profile file",
+			port:     8081,
+			addr:     "127.0.0.1:6379",
+			password: "dev_secret",
+		},
+		{
+			name: "placeholder",
+			env: map[string]string{
+				"TEST_DSN":        "root:root@tcp(db:3306)/app",
+				"TEST_REDIS_HOST": "redis",
+			},
+			port:     8081,
+			dsn:      "root:root@tcp(db:3306)/app",
+			addr:     "redis:6379",
+			password: "dev_secret",
+		},
+		{
+			name: "env overrides profile file",
+			env: map[string]string{
+				"APP_HTTP_PORT":      "9090",
+				"APP_REDIS_PASSWORD": "env_secret",
+			},
+			port:     9090,
+			addr:     "127.0.0.1:6379",
+			password: "env_secret",
+		},
+		{
+			name: "overrides take precedence over env",
+			env: map[string]string{
+				"APP_HTTP_PORT": "9090",
+			},
+			overrides: []string{"http.port=9091", "redis.addr=10.0.0.1:6379"},
+			port:      9091,
+			addr:      "10.0.0.1:6379",
+			password:  "dev_secret",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PROFILE_ENV, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			c := newTestBaseConfig()
+			if err := Load(filepath.Join(dir, "app.yaml"), &c, tt.overrides...); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if c.Http.Port != tt.port {
+				t.Errorf("port = %d, want %d", c.Http.Port, tt.port)
+			}
+			if c.Database.Dsn != tt.dsn {
+				t.Errorf("dsn = %s, want %s", c.Database.Dsn, tt.dsn)
+			}
+			if c.Redis.Addr != tt.addr {
+				t.Errorf("addr = %s, want %s", c.Redis.Addr, tt.addr)
+			}
+			if c.Redis.Password != tt.password {
+				t.Errorf("password = %s, want %s", c.Redis.Password, tt.password)
+			}
+			if len(c.Http.TraceIgnorePaths) != 1 || c.Http.TraceIgnorePaths[0] != "/ping" {
+				t.Errorf("traceignorepaths = %v, want [/ping]", c.Http.TraceIgnorePaths)
+			}
+		})
+	}
+}
+
+func TestLoadUnknownOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.yaml"), "app:\n  name: app\n")
+	c := newTestBaseConfig()
+	if err := Load(filepath.Join(dir, "app.yaml"), &c, "http.unknown=1"); err == nil {
+		t.Error("Load() expect error for unknown override key")
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.yaml"), `app:
+  name: app
+http:
+  prot: 8080
+  session:
+    maxage: abc
+  rpc:
+    ipproxy:
+      proxy:
+        "*": http://127.0.0.1:8080
+`)
+	c := newTestBaseConfig()
+	err := Load(filepath.Join(dir, "app.yaml"), &c)
+	if err == nil {
+		t.Fatal("Load() expect error")
+	}
+	for _, want := range []string{`line 4: unknown config key "http.prot"`, "line 6: cannot unmarshal"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want contains %s", err, want)
+		}
+	}
+}
+
+func TestBaseConfigValidate(t *testing.T) {
+	c := newTestBaseConfig()
+	c.Http.Rpc.Type = "IpProxy"
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() default config error = %v", err)
+	}
+	c.Http.Port = 70000
+	c.Http.Session.StoreType = "redis"
+	c.Http.PeriodLimit.Enable = true
+	c.Http.PeriodLimit.Quota = 0
+	c.Http.Rpc.Type = "consul"
+	c.Log.Format = "xml"
+	c.Log.Modules = map[string]string{"db": "trace"}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() expect error")
+	}
+	for _, want := range []string{"http.port", "redis.addr", "http.periodlimit.quota", "http.rpc.type", "log.format", "log.modules.db"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want contains %s", err, want)
+		}
+	}
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encDsn, err := Encrypt(key, "root:secret@tcp(db:3306)/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encPassword, err := Encrypt(key, "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	writeFile(t, file, "database:\n  dsn: "+encDsn+"\nredis:\n  password: "+encPassword+"\n")
+	t.Setenv(PROFILE_ENV, "")
+	t.Setenv(CONFIG_KEY_ENV, base64.StdEncoding.EncodeToString(key))
+
+	c := newTestBaseConfig()
+	if err := Load(file, &c); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Database.Dsn != "root:secret@tcp(db:3306)/app" || c.Redis.Password != "123456" {
+		t.Errorf("decrypted dsn = %s, password = %s", c.Database.Dsn, c.Redis.Password)
+	}
+
+	t.Setenv(CONFIG_KEY_ENV, base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210")))
+	c = newTestBaseConfig()
+	err = Load(file, &c)
+	if err == nil || !strings.Contains(err.Error(), "database.dsn") || !strings.Contains(err.Error(), "redis.password") {
+		t.Errorf("Load() error = %v, want decrypt error with key path", err)
+	}
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ENV_PREFIX 覆盖配置项的环境变量前缀，如APP_HTTP_PORT对应http.port
+const ENV_PREFIX = "APP_"
+
+// ${ENV_NAME} 或 ${ENV_NAME:default}
+var placeholderRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::([^}]*))?\}`)
+
+// Overrides 命令行覆盖配置项，实现flag.Value，格式为key=value，如http.port=8080
+//
+//	var overrides config.Overrides
+//	flag.Var(&overrides, "set", "override config item, e.g. -set http.port=8080")
+type Overrides []string
+
+func (o *Overrides) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *Overrides) Set(s string) error {
+	if !strings.Contains(s, "=") {
+		return fmt.Errorf("invalid override %q, expect key=value", s)
+	}
+	*o = append(*o, s)
+	return nil
+}
+
+// expandPlaceholders 替换${ENV_NAME:default}占位符，环境变量未设置时使用默认值
+func expandPlaceholders(s string) string {
+	return placeholderRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sub := placeholderRegexp.FindStringSubmatch(m)
+		if value, ok := os.LookupEnv(sub[1]); ok {
+			return value
+		}
+		return sub[2]
+	})
+}
+
+// interpolateNode 替换yaml节点中所有标量值的占位符
+func interpolateNode(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode {
+		expanded := expandPlaceholders(n.Value)
+		if expanded != n.Value {
+			n.Value = expanded
+			// 未加引号的值按替换后的内容重新推断类型，如port: ${PORT:8080}
+			if n.Style == 0 && n.Tag != dotenvTag {
+				n.Tag = ""
+			}
+		}
+	}
+	for _, c := range n.Content {
+		interpolateNode(c)
+	}
+}
+
+// applyEnv 使用APP_<PATH>环境变量覆盖配置项
+func applyEnv(v any) error {
+	secrets := &secretResolver{}
+	return walkFields(v, func(path []string, field reflect.Value) error {
+		name := ENV_PREFIX + strings.ToUpper(strings.Join(path, "_"))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		value, err := secrets.resolve(value)
+		if err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+		if err := setValue(field, value); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// applyOverrides 使用key=value覆盖配置项，key为小写的yaml路径，如http.session.storetype
+func applyOverrides(v any, overrides []string) error {
+	secrets := &secretResolver{}
+	for _, o := range overrides {
+		key, value, ok := strings.Cut(o, "=")
+		if !ok {
+			return fmt.Errorf("invalid override %q, expect key=value", o)
+		}
+		value, err := secrets.resolve(value)
+		if err != nil {
+			return fmt.Errorf("override %s: %w", key, err)
+		}
+		found := false
+		err = walkFields(v, func(path []string, field reflect.Value) error {
+			if !strings.EqualFold(strings.Join(path, "."), key) {
+				return nil
+			}
+			found = true
+			return setValue(field, value)
+		})
+		if err != nil {
+			return fmt.Errorf("override %s: %w", key, err)
+		}
+		if !found {
+			return fmt.Errorf("override %s: unknown config key", key)
+		}
+	}
+	return nil
+}
+
+// walkFields 遍历v中可覆盖的字段（标量和[]string），path为yaml键名路径，inline字段不增加层级
+func walkFields(v any, fn func(path []string, field reflect.Value) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("config must be a non-nil pointer, got %T", v)
+	}
+	return walkStruct(rv.Elem(), nil, fn)
+}
+
+func walkStruct(rv reflect.Value, path []string, fn func(path []string, field reflect.Value) error) error {
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, inline := yamlFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		field := rv.Field(i)
+		fieldPath := append(append([]string(nil), path...), name)
+		if inline {
+			fieldPath = path
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := walkStruct(field, fieldPath, fn); err != nil {
+				return err
+			}
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if err := fn(fieldPath, field); err != nil {
+				return err
+			}
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.String {
+				if err := fn(fieldPath, field); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// yamlFieldName 与yaml.v3一致：默认键名为小写字段名
+func yamlFieldName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("yaml")
+	name, opts, _ := strings.Cut(tag, ",")
+	inline := strings.Contains(opts, "inline")
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+	return name, inline
+}
+
+func setValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(splitList(value)).Convert(field.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// splitList 逗号分隔的列表，忽略空项，环境变量和.env中的[]string配置项共用
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// CONFIG_KEY_ENV 解密配置的主密钥（base64编码的16/24/32字节AES密钥）
+	CONFIG_KEY_ENV = "CONFIG_KEY"
+	// CONFIG_KEY_FILE_ENV 主密钥文件路径，文件内容为base64编码的密钥，CONFIG_KEY未设置时使用
+	CONFIG_KEY_FILE_ENV = "CONFIG_KEY_FILE"
+)
+
+// 加密值格式：ENC(base64(nonce + AES-GCM密文))，可由gotool encrypt生成
+const (
+	encPrefix = "ENC("
+	encSuffix = ")"
+)
+
+// Encrypt 使用AES-GCM加密明文，返回ENC(...)格式的配置值
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed) + encSuffix, nil
+}
+
+// Decrypt 解密ENC(...)格式的配置值
+func Decrypt(key []byte, value string) (string, error) {
+	if !isEncrypted(value) {
+		return "", errors.New("not an ENC(...) value")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, encPrefix), encSuffix))
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// MasterKey 从环境变量CONFIG_KEY或CONFIG_KEY_FILE指定的文件读取主密钥
+func MasterKey() ([]byte, error) {
+	encoded := os.Getenv(CONFIG_KEY_ENV)
+	if encoded == "" {
+		keyFile := os.Getenv(CONFIG_KEY_FILE_ENV)
+		if keyFile == "" {
+			return nil, fmt.Errorf("master key not found, set %s or %s", CONFIG_KEY_ENV, CONFIG_KEY_FILE_ENV)
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = strings.TrimSpace(string(data))
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix) && strings.HasSuffix(value, encSuffix)
+}
+
+// secretResolver 延迟读取主密钥，配置中没有加密值时不要求设置密钥
+type secretResolver struct {
+	key    []byte
+	keyErr error
+	loaded bool
+}
+
+func (r *secretResolver) resolve(value string) (string, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	if !r.loaded {
+		r.key, r.keyErr = MasterKey()
+		r.loaded = true
+	}
+	if r.keyErr != nil {
+		return "", r.keyErr
+	}
+	plaintext, err := Decrypt(r.key, value)
+	if err != nil {
+		return "", fmt.Errorf("decrypt failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// decryptNode 解密yaml中所有ENC(...)值，错误信息带配置项路径
+func (r *secretResolver) decryptNode(n *yaml.Node, prefix string) []string {
+	var errs []string
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			errs = append(errs, r.decryptNode(c, prefix)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			errs = append(errs, r.decryptNode(n.Content[i+1], prefix+n.Content[i].Value+".")...)
+		}
+	case yaml.ScalarNode:
+		plaintext, err := r.resolve(n.Value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %s: %v", n.Line, strings.TrimSuffix(prefix, "."), err))
+		} else if plaintext != n.Value {
+			n.Value = plaintext
+			if n.Tag != dotenvTag {
+				n.Tag = "!!str"
+				n.Style = yaml.DoubleQuotedStyle
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 配置文档格式
+const (
+	FORMAT_YAML   = "yaml"
+	FORMAT_JSON   = "json"
+	FORMAT_TOML   = "toml"
+	FORMAT_DOTENV = "env"
+)
+
+// Source 配置源，将配置文档解析为yaml节点，统一走Load的解码流程（占位符、解密、严格解码）
+type Source interface {
+	// Name 用于错误信息，如文件路径或URL
+	Name() string
+	Load() (*yaml.Node, error)
+}
+
+// LoadSources 按顺序加载多个配置源，后面的覆盖前面的，最后应用环境变量覆盖并校验BaseConfig
+//
+//	err := config.LoadSources(&c,
+//		config.FileSource{Path: "etc/app.yaml"},
+//		config.FileSource{Path: ".env", Optional: true},
+//		config.HttpSource{Url: "http://config-center/app.json"},
+//	)
+func LoadSources(v any, sources ...Source) error {
+	detachValue(v)
+	var errs []error
+	for _, source := range sources {
+		node, err := source.Load()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+		if node == nil {
+			continue
+		}
+		errs = append(errs, decodeNode(source.Name(), node, v))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return applyExternal(v, nil)
+}
+
+// FileSource 本地配置文件，Format为空时按扩展名识别，Optional为true时文件不存在不报错
+type FileSource struct {
+	Path     string
+	Format   string
+	Optional bool
+}
+
+func (s FileSource) Name() string {
+	return s.Path
+}
+
+func (s FileSource) Load() (*yaml.Node, error) {
+	data, err := os.ReadFile(s.Path)
+	if s.Optional && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	format := s.Format
+	if format == "" {
+		format = formatOf(s.Path)
+	}
+	return parseDocument(format, data)
+}
+
+// HttpSource 从HTTP接口拉取配置文档，如配置中心。Format为空时按Content-Type或URL扩展名识别。
+type HttpSource struct {
+	Url    string
+	Format string
+	Header http.Header
+	// Timeout 默认5秒
+	Timeout time.Duration
+}
+
+func (s HttpSource) Name() string {
+	return s.Url
+}
+
+func (s HttpSource) Load() (*yaml.Node, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range s.Header {
+		for _, value := range values {
+			request.Header.Add(k, value)
+		}
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	format := s.Format
+	if format == "" {
+		format = formatOfContentType(response.Header.Get("Content-Type"))
+	}
+	if format == "" {
+		format = formatOf(response.Request.URL.Path)
+	}
+	return parseDocument(format, data)
+}
+
+func formatOf(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return FORMAT_JSON
+	case ".toml":
+		return FORMAT_TOML
+	case ".env":
+		return FORMAT_DOTENV
+	}
+	if path.Base(name) == ".env" {
+		return FORMAT_DOTENV
+	}
+	return FORMAT_YAML
+}
+
+func formatOfContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasSuffix(mediaType, "json"):
+		return FORMAT_JSON
+	case strings.HasSuffix(mediaType, "toml"):
+		return FORMAT_TOML
+	case strings.HasSuffix(mediaType, "yaml"):
+		return FORMAT_YAML
+	}
+	return ""
+}
+
+func parseDocument(format string, data []byte) (*yaml.Node, error) {
+	switch format {
+	case FORMAT_YAML, FORMAT_JSON:
+		// JSON是YAML的子集，直接按YAML解析以保留行号
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		if node.Kind == 0 {
+			return nil, nil
+		}
+		return &node, nil
+	case FORMAT_TOML:
+		var m map[string]any
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		var node yaml.Node
+		if err := node.Encode(m); err != nil {
+			return nil, err
+		}
+		return &node, nil
+	case FORMAT_DOTENV:
+		return parseDotenv(data)
+	}
+	return nil, fmt.Errorf("unknown config format %q", format)
+}
+
+// dotenvTag 标记.env中的值，解码前按目标字段类型转换，见resolveDotenvNode
+const dotenvTag = "!env"
+
+// parseDotenv 解析.env文件，只读取APP_前缀的变量，按_拆分为配置项路径，如APP_HTTP_PORT=8080对应http.port
+func parseDotenv(data []byte) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expect KEY=VALUE", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if !strings.HasPrefix(key, ENV_PREFIX) {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		segments := strings.Split(strings.ToLower(strings.TrimPrefix(key, ENV_PREFIX)), "_")
+		setDotenvValue(root, segments, &yaml.Node{Kind: yaml.ScalarNode, Tag: dotenvTag, Value: value, Line: lineNo})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func setDotenvValue(n *yaml.Node, segments []string, value *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != segments[0] {
+			continue
+		}
+		if len(segments) == 1 {
+			n.Content[i+1] = value
+		} else if n.Content[i+1].Kind == yaml.MappingNode {
+			setDotenvValue(n.Content[i+1], segments[1:], value)
+		}
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: segments[0], Line: value.Line}
+	if len(segments) == 1 {
+		n.Content = append(n.Content, key, value)
+		return
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	n.Content = append(n.Content, key, child)
+	setDotenvValue(child, segments[1:], value)
+}
+
+// resolveDotenvNode 与环境变量覆盖一致，在替换占位符和解密之后：[]string字段的值按逗号拆分为列表，其余值按yaml推断类型
+func resolveDotenvNode(n *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case n.Kind == yaml.ScalarNode && n.Tag == dotenvTag:
+		n.Tag = ""
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String {
+			items := splitList(n.Value)
+			n.Kind, n.Value = yaml.SequenceNode, ""
+			for _, item := range items {
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item, Line: n.Line})
+			}
+		}
+	case n.Kind == yaml.DocumentNode:
+		for _, c := range n.Content {
+			resolveDotenvNode(c, t)
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Type)
+		collectFields(t, fields)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if ft, ok := fields[n.Content[i].Value]; ok {
+				resolveDotenvNode(n.Content[i+1], ft)
+			}
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			resolveDotenvNode(n.Content[i+1], t.Elem())
+		}
+	}
+}
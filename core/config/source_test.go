@@ -0,0 +1,80 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.yaml"), "app:\n  name: app\nhttp:\n  port: 8080\n  session:\n    name: yaml_session\n")
+	writeFile(t, filepath.Join(dir, "app.json"), `{"http": {"session": {"storetype": "cookie"}}, "redis": {"addr": "json:6379"}}`)
+	writeFile(t, filepath.Join(dir, "app.toml"), "[http]\nport = 8081\n[http.periodlimit]\nquota = 50\n")
+	writeFile(t, filepath.Join(dir, ".env"), "# comment\nexport APP_REDIS_PASSWORD=\"env_secret\"\nAPP_HTTP_PERIODLIMIT_PERIOD=10\nOTHER=1\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"http": {"port": 8082}, "redis": {"addr": "remote:6379"}}`))
+	}))
+	defer server.Close()
+
+	c := newTestBaseConfig()
+	err := LoadSources(&c,
+		FileSource{Path: filepath.Join(dir, "app.yaml")},
+		FileSource{Path: filepath.Join(dir, "app.json")},
+		FileSource{Path: filepath.Join(dir, "app.toml")},
+		FileSource{Path: filepath.Join(dir, ".env")},
+		FileSource{Path: filepath.Join(dir, "missing.yaml"), Optional: true},
+		HttpSource{Url: server.URL, Header: http.Header{"Authorization": {"Bearer token"}}},
+	)
+	if err != nil {
+		t.Fatalf("LoadSources() error = %v", err)
+	}
+	if c.Http.Port != 8082 {
+		t.Errorf("port = %d, want 8082", c.Http.Port)
+	}
+	if c.Http.Session.Name != "yaml_session" || c.Http.Session.StoreType != "cookie" {
+		t.Errorf("session = %+v", c.Http.Session)
+	}
+	if c.Http.PeriodLimit.Quota != 50 || c.Http.PeriodLimit.Period != 10 {
+		t.Errorf("periodlimit = %+v", c.Http.PeriodLimit)
+	}
+	if c.Redis.Addr != "remote:6379" || c.Redis.Password != "env_secret" {
+		t.Errorf("redis = %+v", c.Redis)
+	}
+
+	c = newTestBaseConfig()
+	err = LoadSources(&c, FileSource{Path: filepath.Join(dir, "app.yaml")}, HttpSource{Url: server.URL})
+	if err == nil {
+		t.Error("LoadSources() expect error for unauthorized http source")
+	}
+}
+
+func TestDotenvList(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".env"), "APP_HTTP_TRACEIGNOREPATHS=/ping, /metrics,\nAPP_HTTP_PORT=8080\n")
+	c := newTestBaseConfig()
+	if err := LoadSources(&c, FileSource{Path: filepath.Join(dir, ".env")}); err != nil {
+		t.Fatalf("LoadSources() error = %v", err)
+	}
+	if c.Http.Port != 8080 {
+		t.Errorf("port = %d, want 8080", c.Http.Port)
+	}
+	dotenv := c.Http.TraceIgnorePaths
+
+	t.Setenv("APP_HTTP_TRACEIGNOREPATHS", "/ping, /metrics,")
+	c = newTestBaseConfig()
+	if err := LoadSources(&c); err != nil {
+		t.Fatalf("LoadSources() error = %v", err)
+	}
+	want := []string{"/ping", "/metrics"}
+	if !reflect.DeepEqual(dotenv, want) || !reflect.DeepEqual(c.Http.TraceIgnorePaths, want) {
+		t.Errorf("traceignorepaths .env = %q, env = %q, want %q", dotenv, c.Http.TraceIgnorePaths, want)
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+var sessionStoreTypes = []string{"", "memory", "cookie", "redis", "none"}
+
+var rpcTypes = []string{"ipproxy", "kubernetes"}
+
+var logLevels = []string{"", "debug", "info", "warn", "error"}
+
+var logFormats = []string{"", "text", "json"}
+
+var traceExporters = []string{"", "otlp", "stdout", "file"}
+
+// Validate 校验基础配置，返回所有错误的聚合
+func (c BaseConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, key string, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.App.Name != "", "app.name", "must not be empty")
+
+	http := c.Http
+	check(http.Port >= 0 && http.Port <= 65535, "http.port", "%d out of range [0, 65535]", http.Port)
+	check((http.CertFile == "") == (http.KeyFile == ""), "http.certfile", "certfile and keyfile must be set together")
+	check(http.MaxConns >= 0, "http.maxconns", "must not be negative")
+	check(http.MaxBytes >= 0, "http.maxbytes", "must not be negative")
+	check(http.Timeout >= 0, "http.timeout", "must not be negative")
+	check(http.ShutdownTimeout >= 0, "http.shutdowntimeout", "must not be negative")
+	check(http.ShutdownDelay >= 0, "http.shutdowndelay", "must not be negative")
+	check(http.CpuThreshold >= 0 && http.CpuThreshold < 1000, "http.cputhreshold", "%d out of range [0, 1000)", http.CpuThreshold)
+
+	storeType := http.Session.StoreType
+	check(slices.Contains(sessionStoreTypes, storeType), "http.session.storetype", "%q not in memory/cookie/redis/none", storeType)
+	check(storeType != "redis" || c.Redis.Addr != "", "redis.addr", "required by redis session store")
+
+	limit := http.PeriodLimit
+	if limit.Enable {
+		check(limit.Period > 0, "http.periodlimit.period", "must be positive")
+		check(limit.Quota > 0, "http.periodlimit.quota", "must be positive")
+		check(!limit.Distributed || c.Redis.Addr != "", "redis.addr", "required by distributed period limit")
+	}
+
+	if c.Http.OAuth2.Enable {
+		check(c.Http.OAuth2.Expire > 0, "http.oauth2.expire", "must be positive")
+		check(strings.HasPrefix(c.Http.OAuth2.TokenUri, "/"), "http.oauth2.tokenuri", "must start with /")
+	}
+
+	accessLog := c.Http.AccessLog
+	for pattern, ratio := range accessLog.Sample {
+		check(ratio >= 0 && ratio <= 1, "http.accesslog.sample", "%s: %v out of range [0, 1]", pattern, ratio)
+	}
+	for _, pattern := range append(append(append([]string{}, accessLog.SkipPaths...), accessLog.BodyPaths...), c.Http.TraceIgnorePaths...) {
+		_, err := path.Match(pattern, "")
+		check(err == nil, "http.accesslog", "invalid path pattern %q", pattern)
+	}
+	check(accessLog.SlowThreshold >= 0, "http.accesslog.slowthreshold", "must not be negative")
+	check(accessLog.BodyLimit >= 0, "http.accesslog.bodylimit", "must not be negative")
+
+	webhook := c.Http.Recovery.Webhook
+	check(webhook == "" || strings.HasPrefix(webhook, "http://") || strings.HasPrefix(webhook, "https://"),
+		"http.recovery.webhook", "%q must be an http(s) url", webhook)
+
+	if c.Http.Metrics.Enable {
+		check(strings.HasPrefix(c.Http.Metrics.Path, "/"), "http.metrics.path", "must start with /")
+	}
+	if c.Http.OpenApi.Enable {
+		check(strings.HasPrefix(c.Http.OpenApi.Path, "/"), "http.openapi.path", "must start with /")
+		check(strings.HasPrefix(c.Http.OpenApi.SwaggerPath, "/"), "http.openapi.swaggerpath", "must start with /")
+		assets := c.Http.OpenApi.SwaggerAssets
+		check(strings.HasPrefix(assets, "/") || strings.HasPrefix(assets, "http://") || strings.HasPrefix(assets, "https://"),
+			"http.openapi.swaggerassets", "%q must be an http(s) url or start with /", assets)
+	}
+
+	admin := c.Http.Admin
+	if admin.Enable {
+		check(admin.Port >= 0 && admin.Port <= 65535, "http.admin.port", "%d out of range [0, 65535]", admin.Port)
+		check(admin.Port == 0 || admin.Port != http.Port, "http.admin.port", "must differ from http.port")
+		check(admin.Username != "" || c.Http.Rpc.Token != "", "http.admin.username", "basic auth or http.rpc.token required")
+		check(admin.Username == "" || admin.Password != "", "http.admin.password", "required by basic auth")
+	}
+
+	logLevel := strings.ToLower(c.Log.Level)
+	check(slices.Contains(logLevels, logLevel), "log.level", "%q not in debug/info/warn/error", c.Log.Level)
+	check(slices.Contains(logFormats, strings.ToLower(c.Log.Format)), "log.format", "%q not in text/json", c.Log.Format)
+	check(c.Log.MaxSize >= 0, "log.maxsize", "must not be negative")
+	check(c.Log.MaxAge >= 0, "log.maxage", "must not be negative")
+	check(c.Log.MaxBackups >= 0, "log.maxbackups", "must not be negative")
+	if c.Log.Async.Enable {
+		check(c.Log.Async.QueueSize >= 0, "log.async.queuesize", "must not be negative")
+		check(c.Log.Async.FlushInterval >= 0, "log.async.flushinterval", "must not be negative")
+	}
+	for module, l := range c.Log.Modules {
+		check(slices.Contains(logLevels, strings.ToLower(l)), "log.modules."+module, "%q not in debug/info/warn/error", l)
+	}
+
+	if c.Trace.Enable {
+		exporter := strings.ToLower(c.Trace.Exporter)
+		check(slices.Contains(traceExporters, exporter), "trace.exporter", "%q not in otlp/stdout/file", c.Trace.Exporter)
+		check(exporter != "otlp" || c.Trace.Endpoint != "", "trace.endpoint", "required by otlp exporter")
+		check(exporter != "file" || c.Trace.File != "", "trace.file", "required by file exporter")
+		check(c.Trace.SampleRatio >= 0 && c.Trace.SampleRatio <= 1, "trace.sampleratio", "%v out of range [0, 1]", c.Trace.SampleRatio)
+	}
+
+	rpcType := strings.ToLower(c.Http.Rpc.Type)
+	check(slices.Contains(rpcTypes, rpcType), "http.rpc.type", "unknown type %q, expect IpProxy or Kubernetes", c.Http.Rpc.Type)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 框架支持热更新的配置项，其余配置项变更后需重启才能生效
+var reloadableKeys = []string{
+	"http.periodlimit.period",
+	"http.periodlimit.quota",
+	"http.rpc.token",
+	"http.rpc.ipproxy.proxy",
+	"http.rpc.kubernetes.namespace",
+	"http.rpc.kubernetes.proxy",
+	"http.traceignorepaths",
+	"http.accesslog",
+	"log.level",
+	"log.modules",
+}
+
+var reloadableLock sync.RWMutex
+
+// RegisterReloadable 注册应用自身支持热更新的配置项，如biz.switch，支持前缀匹配
+func RegisterReloadable(keys ...string) {
+	reloadableLock.Lock()
+	defer reloadableLock.Unlock()
+	reloadableKeys = append(reloadableKeys, keys...)
+}
+
+func isReloadable(key string) bool {
+	reloadableLock.RLock()
+	defer reloadableLock.RUnlock()
+	for _, k := range reloadableKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// BaseConfigSubscriber 可订阅BaseConfig变更，由Watcher实现，供rest.Server热更新框架组件
+type BaseConfigSubscriber interface {
+	SubscribeBase(fn func(old, new *BaseConfig))
+}
+
+// Watcher 轮询配置文件（或挂载的ConfigMap目录），内容变化时重新加载并通知订阅者。
+// 新配置由newValue创建后重新Load，代码中设置的字段（如Database.Dialector）不会带入新配置，
+// 订阅者应只读取可热更新的配置项。
+type Watcher[T any] struct {
+	// Interval 轮询间隔，默认5秒
+	Interval time.Duration
+
+	path        string
+	newValue    func() *T
+	overrides   []string
+	lock        sync.RWMutex
+	current     *T
+	hash        [sha256.Size]byte
+	subscribers []func(old, new *T)
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewWatcher 加载配置并创建Watcher，path可以是配置文件或配置目录
+func NewWatcher[T any](path string, newValue func() *T, overrides ...string) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		Interval:  5 * time.Second,
+		path:      path,
+		newValue:  newValue,
+		overrides: overrides,
+		stop:      make(chan struct{}),
+	}
+	v, hash, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.current = v
+	w.hash = hash
+	return w, nil
+}
+
+// Get 返回当前配置，调用方不应修改返回值
+func (w *Watcher[T]) Get() *T {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.current
+}
+
+// Subscribe 订阅配置变更
+func (w *Watcher[T]) Subscribe(fn func(old, new *T)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// SubscribeBase 订阅T中BaseConfig的变更
+func (w *Watcher[T]) SubscribeBase(fn func(old, new *BaseConfig)) {
+	w.Subscribe(func(old, new *T) {
+		oldBase, newBase := baseConfigOf(old), baseConfigOf(new)
+		if oldBase != nil && newBase != nil {
+			fn(oldBase, newBase)
+		}
+	})
+}
+
+// Start 启动轮询
+func (w *Watcher[T]) Start() {
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.Reload()
+			}
+		}
+	}()
+	slog.Info("Watching config,", "path", w.path, "interval", w.Interval)
+}
+
+// Stop 停止轮询
+func (w *Watcher[T]) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Reload 检查配置文件是否变化，变化则重新加载并通知订阅者。加载失败时保留原配置。
+func (w *Watcher[T]) Reload() {
+	hash, err := w.fileHash()
+	if err != nil {
+		slog.Error("Read config failed, keep current config.", "path", w.path, "error", err)
+		return
+	}
+	w.lock.RLock()
+	unchanged := hash == w.hash
+	w.lock.RUnlock()
+	if unchanged {
+		return
+	}
+	v, hash, err := w.load()
+	if err != nil {
+		slog.Error("Reload config failed, keep current config.", "path", w.path, "error", err)
+		return
+	}
+	w.lock.Lock()
+	old := w.current
+	w.current = v
+	w.hash = hash
+	subscribers := append([]func(old, new *T){}, w.subscribers...)
+	w.lock.Unlock()
+
+	changed := diffKeys(old, v)
+	if len(changed) == 0 {
+		return
+	}
+	for _, key := range changed {
+		if isReloadable(key) {
+			slog.Info("Config reloaded,", "key", key)
+		} else {
+			slog.Warn("Config changed but not reloadable, restart required,", "key", key)
+		}
+	}
+	for _, fn := range subscribers {
+		w.notify(fn, old, v)
+	}
+}
+
+func (w *Watcher[T]) notify(fn func(old, new *T), old, new *T) {
+	defer func() {
+		if err := recover(); err != nil {
+			slog.Error("Config subscriber panic.", "error", err)
+		}
+	}()
+	fn(old, new)
+}
+
+func (w *Watcher[T]) load() (*T, [sha256.Size]byte, error) {
+	hash, err := w.fileHash()
+	if err != nil {
+		return nil, hash, err
+	}
+	basePath, err := w.basePath()
+	if err != nil {
+		return nil, hash, err
+	}
+	v := w.newValue()
+	if err := Load(basePath, v, w.overrides...); err != nil {
+		return nil, hash, err
+	}
+	return v, hash, nil
+}
+
+func (w *Watcher[T]) basePath() (string, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return w.path, nil
+	}
+	base, err := findBaseFileInDir(w.path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(w.path, base), nil
+}
+
+// fileHash 计算基础配置及其所有profile配置文件的内容摘要。
+// ConfigMap通过替换..data软链接更新，按内容而非修改时间判断变化。
+func (w *Watcher[T]) fileHash() ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	basePath, err := w.basePath()
+	if err != nil {
+		return hash, err
+	}
+	ext := filepath.Ext(basePath)
+	profiles, err := filepath.Glob(strings.TrimSuffix(basePath, ext) + "-*" + ext)
+	if err != nil {
+		return hash, err
+	}
+	sort.Strings(profiles)
+	h := sha256.New()
+	for _, f := range append([]string{basePath}, profiles...) {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return hash, err
+		}
+		fmt.Fprintf(h, "%s\n%d\n", f, len(data))
+		h.Write(data)
+	}
+	copy(hash[:], h.Sum(nil))
+	return hash, nil
+}
+
+// diffKeys 返回两份配置中值不同的配置项路径，map和slice整体比较，interface{}字段忽略
+func diffKeys(old, new any) []string {
+	var keys []string
+	diffValue(reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), nil, &keys)
+	return keys
+}
+
+func diffValue(old, new reflect.Value, path []string, keys *[]string) {
+	switch old.Kind() {
+	case reflect.Struct:
+		rt := old.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			sf := rt.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, inline := yamlFieldName(sf)
+			if name == "-" {
+				continue
+			}
+			fieldPath := append(append([]string(nil), path...), name)
+			if inline {
+				fieldPath = path
+			}
+			diffValue(old.Field(i), new.Field(i), fieldPath, keys)
+		}
+	case reflect.Interface, reflect.Func, reflect.Chan:
+	default:
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*keys = append(*keys, strings.Join(path, "."))
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWatcherReload(t *testing.T) {
+	t.Setenv(PROFILE_ENV, "")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	writeFile(t, file, "http:\n  port: 8080\n  periodlimit:\n    quota: 100\n")
+	w, err := NewWatcher(dir, func() *BaseConfig {
+		c := newTestBaseConfig()
+		return &c
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	var oldQuota, newQuota int
+	notified := 0
+	w.SubscribeBase(func(old, new *BaseConfig) {
+		notified++
+		oldQuota, newQuota = old.Http.PeriodLimit.Quota, new.Http.PeriodLimit.Quota
+	})
+
+	w.Reload()
+	if notified != 0 {
+		t.Fatalf("notified = %d without change", notified)
+	}
+
+	writeFile(t, file, "http:\n  port: 9090\n  periodlimit:\n    quota: 200\n")
+	w.Reload()
+	if notified != 1 || oldQuota != 100 || newQuota != 200 {
+		t.Errorf("notified = %d, quota %d -> %d, want 1, 100 -> 200", notified, oldQuota, newQuota)
+	}
+	if w.Get().Http.Port != 9090 {
+		t.Errorf("port = %d, want 9090", w.Get().Http.Port)
+	}
+
+	// 解析失败时保留原配置
+	writeFile(t, file, "http:\n  port: abc\n")
+	w.Reload()
+	if notified != 1 || w.Get().Http.Port != 9090 {
+		t.Errorf("invalid config should be ignored, notified = %d, port = %d", notified, w.Get().Http.Port)
+	}
+}
+
+// ConfigMap挂载目录：..<timestamp>/中为真实文件，..data指向当前版本，app.yaml指向..data/app.yaml
+func TestWatcherConfigMap(t *testing.T) {
+	t.Setenv(PROFILE_ENV, "")
+	dir := t.TempDir()
+	writeVersion := func(name, content string) {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, name, "app.yaml"), content)
+		link := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(name, link); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(link, filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVersion("..2026_01", "http:\n  port: 8080\n")
+	if err := os.Symlink(filepath.Join("..data", "app.yaml"), filepath.Join(dir, "app.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(dir, func() *BaseConfig {
+		c := newTestBaseConfig()
+		return &c
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	if w.Get().Http.Port != 8080 {
+		t.Fatalf("port = %d, want 8080", w.Get().Http.Port)
+	}
+	notified := 0
+	w.SubscribeBase(func(old, new *BaseConfig) { notified++ })
+
+	writeVersion("..2026_02", "http:\n  port: 9090\n")
+	w.Reload()
+	if notified != 1 || w.Get().Http.Port != 9090 {
+		t.Errorf("notified = %d, port = %d, want 1, 9090", notified, w.Get().Http.Port)
+	}
+}
+
+func TestDiffKeys(t *testing.T) {
+	old, new := newTestBaseConfig(), newTestBaseConfig()
+	new.Http.Port = 9090
+	new.Http.PeriodLimit.Quota = 1
+	new.Http.TraceIgnorePaths = []string{"/ping"}
+	keys := diffKeys(&old, &new)
+	want := map[string]bool{"http.port": false, "http.periodlimit.quota": true, "http.traceignorepaths": true}
+	if len(keys) != len(want) {
+		t.Fatalf("diffKeys() = %v", keys)
+	}
+	for _, k := range keys {
+		reloadable, ok := want[k]
+		if !ok || isReloadable(k) != reloadable {
+			t.Errorf("key %s reloadable = %v", k, isReloadable(k))
+		}
+	}
+}
+
+func TestWatcherReloadMap(t *testing.T) {
+	t.Setenv(PROFILE_ENV, "")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	writeFile(t, file, "http:\n  rpc:\n    ipproxy:\n      proxy:\n        user: http://user:8080\n")
+	defaults := newTestBaseConfig()
+	defaults.Http.Rpc.IpProxy.Proxy = map[string]string{"*": "http://127.0.0.1:8080"}
+	w, err := NewWatcher(dir, func() *BaseConfig {
+		c := defaults
+		return &c
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	notified := 0
+	w.SubscribeBase(func(old, new *BaseConfig) {
+		notified++
+	})
+
+	writeFile(t, file, "http:\n  rpc:\n    ipproxy:\n      proxy:\n        order: http://order:8080\n")
+	w.Reload()
+	if notified != 1 {
+		t.Errorf("notified = %d, want 1", notified)
+	}
+	want := map[string]string{"*": "http://127.0.0.1:8080", "order": "http://order:8080"}
+	if got := w.Get().Http.Rpc.IpProxy.Proxy; !reflect.DeepEqual(got, want) {
+		t.Errorf("proxy = %v, want %v", got, want)
+	}
+	// 默认值不被修改
+	if len(defaults.Http.Rpc.IpProxy.Proxy) != 1 {
+		t.Errorf("defaults modified: %v", defaults.Http.Rpc.IpProxy.Proxy)
+	}
+}
@@ -1,35 +1,22 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
-	"log/slog"
-	"time"
 
 	"github.com/kappere/go-rest/core/config/conf"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 )
 
-func NewDatabase(dbConf conf.DatabaseConfig, debug bool) *gorm.DB {
+// NewDatabase 创建数据库连接，dsn为空时返回nil。SQL日志输出到db模块日志，由log.modules.db控制级别
+func NewDatabase(dbConf conf.DatabaseConfig) *gorm.DB {
 	if dbConf.Dsn == "" {
 		return nil
 	}
-	sqlLogger := logger.New(
-		log.Default(),
-		logger.Config{
-			SlowThreshold:             200 * time.Millisecond,
-			LogLevel:                  logger.Info, // Log level
-			IgnoreRecordNotFoundError: true,        // Ignore ErrRecordNotFound error for logger
-			ParameterizedQueries:      false,
-			Colorful:                  debug,
-		},
-	)
-
 	db, err := gorm.Open(dbConf.Dialector.(gorm.Dialector), &gorm.Config{
-		Logger: sqlLogger,
+		Logger: newSqlLogger(),
 		NamingStrategy: schema.NamingStrategy{
 			TablePrefix:   "",    // table name prefix, table for `User` would be `t_users`
 			SingularTable: true,  // use singular table name, table for `User` would be `user` with this option enabled
@@ -40,7 +27,10 @@ func NewDatabase(dbConf conf.DatabaseConfig, debug bool) *gorm.DB {
 	if err != nil {
 		panic(err)
 	}
-	slog.Info("Init datasource")
+	if err := errors.Join(registerMetrics(db), registerTrace(db)); err != nil {
+		panic(err)
+	}
+	log.Info("Init datasource")
 	return db
 }
 
@@ -51,7 +41,7 @@ func Transaction(db *gorm.DB, fn func(*gorm.DB) interface{}) interface{} {
 		defer func() {
 			if r := recover(); r != nil {
 				msg := fmt.Sprintf("%s", r)
-				slog.Error("Transaction rollback for error.", "error", msg)
+				log.Error("Transaction rollback for error.", "error", msg)
 				err = errors.New(msg)
 				pnc = r
 			}
@@ -65,3 +55,45 @@ func Transaction(db *gorm.DB, fn func(*gorm.DB) interface{}) interface{} {
 	}
 	return result
 }
+
+// Component 数据库组件，可注册到rest.Server，启动时检查连接，关闭时释放连接池，
+// 同时作为就绪检查项。db为nil（未配置dsn）时不做任何操作
+type Component struct {
+	name string
+	db   *gorm.DB
+}
+
+func NewComponent(name string, db *gorm.DB) *Component {
+	return &Component{name: name, db: db}
+}
+
+func (c *Component) Name() string {
+	return c.name
+}
+
+func (c *Component) Start(ctx context.Context) error {
+	if c.db == nil {
+		return nil
+	}
+	sqlDb, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDb.PingContext(ctx)
+}
+
+func (c *Component) Stop(ctx context.Context) error {
+	if c.db == nil {
+		return nil
+	}
+	sqlDb, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDb.Close()
+}
+
+// Check 就绪检查，ping数据库
+func (c *Component) Check(ctx context.Context) error {
+	return c.Start(ctx)
+}
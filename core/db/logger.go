@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kappere/go-rest/core/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// 超过该耗时的SQL以WARN输出
+const SLOW_SQL_THRESHOLD = 200 * time.Millisecond
+
+var log = logger.Module(logger.MODULE_DB)
+
+// sqlLogger 将gorm日志输出到db模块日志，级别由log.modules.db控制
+type sqlLogger struct {
+	level gormlogger.LogLevel
+}
+
+func newSqlLogger() *sqlLogger {
+	return &sqlLogger{level: gormlogger.Info}
+}
+
+func (l *sqlLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &sqlLogger{level: level}
+}
+
+func (l *sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		log.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		log.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		log.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	latency := time.Since(begin)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.ErrorContext(ctx, "SQL", "sql", sql, "rows", rows, "latency", latency, "error", err)
+	case latency > SLOW_SQL_THRESHOLD && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.WarnContext(ctx, "Slow SQL", "sql", sql, "rows", rows, "latency", latency)
+	case l.level >= gormlogger.Info && log.Enabled(ctx, slog.LevelInfo):
+		sql, rows := fc()
+		log.InfoContext(ctx, "SQL", "sql", sql, "rows", rows, "latency", latency)
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kappere/go-rest/core/metric"
+	"gorm.io/gorm"
+)
+
+var (
+	dbDuration = metric.NewHistogramVec("db_query_duration_seconds",
+		"Database statement latency by operation.", nil, "operation")
+	dbErrors = metric.NewCounterVec("db_errors_total",
+		"Number of failed database statements by operation.", "operation")
+)
+
+const metricStartKey = "gorest:metric_start"
+
+// registerMetrics 通过gorm回调统计各类语句的延迟和错误数，记录不存在不计为错误
+func registerMetrics(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(metricStartKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if start, ok := tx.InstanceGet(metricStartKey); ok {
+				dbDuration.Observe(time.Since(start.(time.Time)).Seconds(), operation)
+			}
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				dbErrors.Inc(operation)
+			}
+		}
+	}
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("metric:before_create", before),
+		callback.Create().After("gorm:create").Register("metric:after_create", after("create")),
+		callback.Query().Before("gorm:query").Register("metric:before_query", before),
+		callback.Query().After("gorm:query").Register("metric:after_query", after("query")),
+		callback.Update().Before("gorm:update").Register("metric:before_update", before),
+		callback.Update().After("gorm:update").Register("metric:after_update", after("update")),
+		callback.Delete().Before("gorm:delete").Register("metric:before_delete", before),
+		callback.Delete().After("gorm:delete").Register("metric:after_delete", after("delete")),
+		callback.Row().Before("gorm:row").Register("metric:before_row", before),
+		callback.Row().After("gorm:row").Register("metric:after_row", after("row")),
+		callback.Raw().Before("gorm:raw").Register("metric:before_raw", before),
+		callback.Raw().After("gorm:raw").Register("metric:after_raw", after("raw")),
+	)
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/kappere/go-rest/core/httpx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Paginate 分页和排序scope。sortable为允许排序的字段名到列名的白名单，
+// 排序字段不在白名单中时查询返回httpx.ErrParam，列名由gorm转义
+//
+//	tx.Scopes(db.Paginate(req.PageRequest, map[string]string{"id": "id", "createdAt": "created_at"})).Find(&users)
+func Paginate(page httpx.PageRequest, sortable map[string]string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return Sort(page, sortable)(tx).Offset(page.Offset()).Limit(page.Limit())
+	}
+}
+
+// Sort 只排序不分页，白名单参见Paginate
+func Sort(page httpx.PageRequest, sortable map[string]string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		columns, err := sortColumns(page, sortable)
+		if err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		for _, column := range columns {
+			tx = tx.Order(column)
+		}
+		return tx
+	}
+}
+
+func sortColumns(page httpx.PageRequest, sortable map[string]string) ([]clause.OrderByColumn, error) {
+	var columns []clause.OrderByColumn
+	for _, field := range page.SortFields() {
+		column, ok := sortable[field.Field]
+		if !ok {
+			return nil, httpx.ErrParam.WithMessage("unsupported sort field: " + field.Field)
+		}
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: field.Desc})
+	}
+	return columns, nil
+}
+
+// FindPage 统计总数并查询当前页，tx中的查询条件同时用于统计和查询
+//
+//	result, err := db.FindPage[model.User](m.db.Where("status = ?", 1), req.PageRequest, sortable)
+func FindPage[T any](tx *gorm.DB, page httpx.PageRequest, sortable map[string]string) (httpx.PageResult[T], error) {
+	// 先校验排序字段，避免总数为0时跳过查询而不报错
+	if _, err := sortColumns(page, sortable); err != nil {
+		return httpx.PageResult[T]{}, err
+	}
+	if tx.Statement.Model == nil {
+		tx = tx.Model(new(T))
+	}
+	var total int64
+	if err := tx.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return httpx.PageResult[T]{}, err
+	}
+	var items []T
+	if total > int64(page.Offset()) {
+		if err := tx.Session(&gorm.Session{}).Scopes(Paginate(page, sortable)).Find(&items).Error; err != nil {
+			return httpx.PageResult[T]{}, err
+		}
+	}
+	return httpx.NewPageResult(items, total, page), nil
+}
+
+// FindByCursor 游标分页，按唯一且有索引的列(如自增id)排序，使用column > cursor代替offset，适用于大表。
+// 不统计总数，Total为-1，NextCursor为空表示没有下一页，忽略page.Page和page.Sort
+func FindByCursor[T any](tx *gorm.DB, page httpx.PageRequest, column string, desc bool) (httpx.PageResult[T], error) {
+	limit := page.Limit()
+	scope, err := cursorScope(page.Cursor, column, desc)
+	if err != nil {
+		return httpx.PageResult[T]{}, err
+	}
+	var items []T
+	// 多查一条判断是否有下一页
+	if err := tx.Scopes(scope).Limit(limit + 1).Find(&items).Error; err != nil {
+		return httpx.PageResult[T]{}, err
+	}
+	result := httpx.NewPageResult(items, -1, page)
+	if len(items) > limit {
+		result.Items = items[:limit]
+		value, err := columnValue(tx, &items[limit-1], column)
+		if err != nil {
+			return httpx.PageResult[T]{}, err
+		}
+		if result.NextCursor, err = encodeCursor(value); err != nil {
+			return httpx.PageResult[T]{}, err
+		}
+	}
+	return result, nil
+}
+
+func cursorScope(cursor string, column string, desc bool) (func(*gorm.DB) *gorm.DB, error) {
+	var condition clause.Expression
+	if cursor != "" {
+		value, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, httpx.ErrParam.WithMessage("invalid cursor").WithCause(err)
+		}
+		if desc {
+			condition = clause.Lt{Column: clause.Column{Name: column}, Value: value}
+		} else {
+			condition = clause.Gt{Column: clause.Column{Name: column}, Value: value}
+		}
+	}
+	return func(tx *gorm.DB) *gorm.DB {
+		if condition != nil {
+			tx = tx.Where(condition)
+		}
+		return tx.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}, nil
+}
+
+// columnValue 通过gorm模型定义读取列的值
+func columnValue(tx *gorm.DB, item any, column string) (any, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(item); err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.LookUpField(column)
+	if field == nil {
+		return nil, fmt.Errorf("column %s not found in %s", column, stmt.Schema.Name)
+	}
+	value, _ := field.ValueOf(context.Background(), reflect.Indirect(reflect.ValueOf(item)))
+	return value, nil
+}
+
+// 游标为列值的JSON，base64编码后对调用方不透明
+func encodeCursor(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// 避免大整数转为float64丢失精度
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case string, bool:
+		return v, nil
+	}
+	return nil, errors.New("unsupported cursor value")
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kappere/go-rest/core/httpx"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+type testUser struct {
+	Id        int64
+	Name      string
+	CreatedAt string
+}
+
+func TestPaginate(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortable := map[string]string{"id": "id", "createdAt": "created_at"}
+	cursor, _ := encodeCursor(int64(9007199254740993))
+	tests := []struct {
+		name    string
+		scope   func(*gorm.DB) *gorm.DB
+		want    string
+		wantErr bool
+	}{
+		{"default", Paginate(httpx.PageRequest{}, sortable), "SELECT * FROM `test_users` LIMIT 20", false},
+		{"page and sort", Paginate(httpx.PageRequest{Page: 3, Size: 10, Sort: "-createdAt, id"}, sortable),
+			"SELECT * FROM `test_users` ORDER BY `created_at` DESC,`id` LIMIT 10 OFFSET 20", false},
+		{"max size", Paginate(httpx.PageRequest{Page: -1, Size: 1000}, sortable), "SELECT * FROM `test_users` LIMIT 100", false},
+		{"sort whitelist", Paginate(httpx.PageRequest{Sort: "name;drop table"}, sortable), "", true},
+		{"first cursor", mustCursorScope(t, "", "id", false), "SELECT * FROM `test_users` ORDER BY `id`", false},
+		{"next cursor desc", mustCursorScope(t, cursor, "id", true),
+			"SELECT * FROM `test_users` WHERE `id` < 9007199254740993 ORDER BY `id` DESC", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []testUser
+			tx := db.Scopes(tt.scope).Find(&users)
+			if tt.wantErr {
+				if !errors.Is(tx.Error, httpx.ErrParam) {
+					t.Errorf("error = %v, want httpx.ErrParam", tx.Error)
+				}
+				return
+			}
+			if tx.Error != nil {
+				t.Fatal(tx.Error)
+			}
+			if got := tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...); got != tt.want {
+				t.Errorf("sql = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := cursorScope("not json", "id", false); !errors.Is(err, httpx.ErrParam) {
+		t.Errorf("invalid cursor error = %v", err)
+	}
+	value, err := columnValue(db, &testUser{Id: 42}, "id")
+	if err != nil || value != int64(42) {
+		t.Errorf("columnValue = %v %v", value, err)
+	}
+}
+
+func mustCursorScope(t *testing.T, cursor, column string, desc bool) func(*gorm.DB) *gorm.DB {
+	scope, err := cursorScope(cursor, column, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scope
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/kappere/go-rest/core/trace"
+	"gorm.io/gorm"
+)
+
+const traceSpanKey = "gorest:trace_span"
+
+// registerTrace 为每条语句创建span，需通过db.WithContext(ctx)传入请求的context，
+// context中没有trace时不创建span
+func registerTrace(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		if trace.FromContext(tx.Statement.Context) == nil {
+			return
+		}
+		_, span := trace.Start(tx.Statement.Context, "gorm", trace.KIND_CLIENT)
+		tx.InstanceSet(traceSpanKey, span)
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(traceSpanKey)
+			if !ok {
+				return
+			}
+			span := value.(*trace.Span)
+			span.Name = "gorm " + operation + " " + tx.Statement.Table
+			span.SetAttr("db.system", tx.Dialector.Name())
+			span.SetAttr("db.operation", operation)
+			span.SetAttr("db.sql.table", tx.Statement.Table)
+			span.SetAttr("db.statement", tx.Statement.SQL.String())
+			span.SetAttr("db.rows_affected", tx.RowsAffected)
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				span.SetError(tx.Error)
+			}
+			span.End()
+		}
+	}
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("trace:before_create", before),
+		callback.Create().After("gorm:create").Register("trace:after_create", after("create")),
+		callback.Query().Before("gorm:query").Register("trace:before_query", before),
+		callback.Query().After("gorm:query").Register("trace:after_query", after("query")),
+		callback.Update().Before("gorm:update").Register("trace:before_update", before),
+		callback.Update().After("gorm:update").Register("trace:after_update", after("update")),
+		callback.Delete().Before("gorm:delete").Register("trace:before_delete", before),
+		callback.Delete().After("gorm:delete").Register("trace:after_delete", after("delete")),
+		callback.Row().Before("gorm:row").Register("trace:before_row", before),
+		callback.Row().After("gorm:row").Register("trace:after_row", after("row")),
+		callback.Raw().Before("gorm:raw").Register("trace:before_raw", before),
+		callback.Raw().After("gorm:raw").Register("trace:after_raw", after("raw")),
+	)
+}
@@ -0,0 +1,208 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// RPC鉴权状态码
+const (
+	STATUS_RPC_TIMESTAMP = -550
+	STATUS_RPC_TOKEN     = -552
+)
+
+// CodeInfo 注册的状态码
+type CodeInfo struct {
+	Code int
+	// HttpStatus 开启http.httpstatus时使用的HTTP状态码
+	HttpStatus int
+	// Message 默认提示
+	Message string
+}
+
+var (
+	codesLock sync.RWMutex
+	codes     = make(map[int]CodeInfo)
+)
+
+// 框架使用的状态码，可在处理函数中直接返回，如return nil, httpx.ErrParam.WithMessage("id is required")
+var (
+	ErrCommon             = RegisterCode(STATUS_ERROR_COMMON, http.StatusInternalServerError, "error")
+	ErrLimit              = RegisterCode(STATUS_ERROR_LIMIT, http.StatusTooManyRequests, "too many requests")
+	ErrTooLarge           = RegisterCode(STATUS_ERROR_TOO_LARGE, http.StatusRequestEntityTooLarge, "request entity too large")
+	ErrTimeout            = RegisterCode(STATUS_ERROR_TIMEOUT, http.StatusServiceUnavailable, "request timeout")
+	ErrParam              = RegisterCode(STATUS_ERROR_PARAM, http.StatusBadRequest, "invalid parameters")
+	ErrServiceUnavailable = RegisterCode(STATUS_ERROR_UNAVAILABLE, http.StatusServiceUnavailable, "service unavailable")
+	ErrNoAuthentication   = RegisterCode(STATUS_NO_AUTHENTICATION, http.StatusUnauthorized, "authentication required")
+	ErrNoAuthorization    = RegisterCode(STATUS_NO_AUTHORIZATION, http.StatusForbidden, "authorization required")
+	ErrRpcTimestamp       = RegisterCode(STATUS_RPC_TIMESTAMP, http.StatusForbidden, "rpc timestamp expired")
+	ErrRpcToken           = RegisterCode(STATUS_RPC_TOKEN, http.StatusForbidden, "invalid rpc token")
+)
+
+// RegisterCode 注册状态码及其HTTP状态码和默认提示，返回该状态码的BizError。
+// 应用状态码在包初始化时注册，重复注册时覆盖。
+//
+//	var ErrUserNotFound = httpx.RegisterCode(-1001, http.StatusNotFound, "user not found")
+func RegisterCode(code int, httpStatus int, message string) *BizError {
+	codesLock.Lock()
+	defer codesLock.Unlock()
+	codes[code] = CodeInfo{Code: code, HttpStatus: httpStatus, Message: message}
+	return &BizError{Code: code, Message: message}
+}
+
+// LookupCode 查询注册的状态码，成功状态码0始终存在
+func LookupCode(code int) (CodeInfo, bool) {
+	if code == STATUS_SUCCESS {
+		return CodeInfo{Code: code, HttpStatus: http.StatusOK, Message: "success"}, true
+	}
+	codesLock.RLock()
+	defer codesLock.RUnlock()
+	info, ok := codes[code]
+	return info, ok
+}
+
+// Codes 所有注册的状态码，按状态码降序
+func Codes() []CodeInfo {
+	codesLock.RLock()
+	result := make([]CodeInfo, 0, len(codes))
+	for _, info := range codes {
+		result = append(result, info)
+	}
+	codesLock.RUnlock()
+	sort.Slice(result, func(i, j int) bool { return result[i].Code > result[j].Code })
+	return result
+}
+
+// BizError 业务错误，由rest.Handle和NiceRecovery渲染为响应，Details作为响应的data
+type BizError struct {
+	Code    int
+	Message string
+	// HttpStatus 非0时始终使用，否则开启http.httpstatus时使用注册的HTTP状态码
+	HttpStatus int
+	Details    any
+	// Cause 内部错误，只用于日志，不返回给调用方
+	Cause error
+}
+
+// NewBizError 创建业务错误，message为空时使用注册的默认提示
+func NewBizError(code int, message string) *BizError {
+	if message == "" {
+		info, _ := LookupCode(code)
+		message = info.Message
+	}
+	return &BizError{Code: code, Message: message}
+}
+
+func (e *BizError) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *BizError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 状态码相同即视为同一错误，errors.Is(err, httpx.ErrParam)
+func (e *BizError) Is(target error) bool {
+	t, ok := target.(*BizError)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage 以下With方法均返回副本，不修改注册的错误
+func (e *BizError) WithMessage(message string) *BizError {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+func (e *BizError) WithDetails(details any) *BizError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+func (e *BizError) WithCause(cause error) *BizError {
+	c := *e
+	c.Cause = cause
+	return &c
+}
+
+func (e *BizError) WithHttpStatus(status int) *BizError {
+	c := *e
+	c.HttpStatus = status
+	return &c
+}
+
+// Status 错误本身的HTTP状态码，未设置时使用注册的HTTP状态码，未注册的状态码为500
+func (e *BizError) Status() int {
+	if e.HttpStatus != 0 {
+		return e.HttpStatus
+	}
+	if info, ok := LookupCode(e.Code); ok {
+		return info.HttpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// Response 使用SetResponse设置的响应格式
+func (e *BizError) Response() Response {
+	return ErrorWithData(e.Message, e.Code, e.Details)
+}
+
+// AsBizError 将错误转换为BizError：错误链中的BizError直接返回，
+// 实现了Code() int的错误使用其状态码，其余为STATUS_ERROR_COMMON
+func AsBizError(err error) *BizError {
+	var bizError *BizError
+	if errors.As(err, &bizError) {
+		return bizError
+	}
+	code := STATUS_ERROR_COMMON
+	var coder interface{ Code() int }
+	if errors.As(err, &coder) {
+		code = coder.Code()
+	}
+	return &BizError{Code: code, Message: err.Error()}
+}
+
+var httpStatusEnabled atomic.Bool
+
+// SetHttpStatus 开启时错误响应使用BizError的HTTP状态码，默认始终返回200并以code表示错误。
+// 为进程级默认值，rest.Server按各自的http.httpstatus通过WithHttpStatusMode为请求设置
+func SetHttpStatus(enable bool) {
+	httpStatusEnabled.Store(enable)
+}
+
+type httpStatusKey struct{}
+
+// WithHttpStatusMode 设置当前请求是否使用BizError的HTTP状态码，覆盖SetHttpStatus
+func WithHttpStatusMode(ctx context.Context, enable bool) context.Context {
+	return context.WithValue(ctx, httpStatusKey{}, enable)
+}
+
+func httpStatusMode(ctx context.Context) bool {
+	if enable, ok := ctx.Value(httpStatusKey{}).(bool); ok {
+		return enable
+	}
+	return httpStatusEnabled.Load()
+}
+
+// HttpStatus 错误响应的HTTP状态码：错误指定了HttpStatus时使用该值，
+// 否则开启http.httpstatus时使用注册的HTTP状态码，关闭时为200。err为nil时为200
+func HttpStatus(ctx context.Context, err *BizError) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if err.HttpStatus != 0 {
+		return err.HttpStatus
+	}
+	if !httpStatusMode(ctx) {
+		return http.StatusOK
+	}
+	return err.Status()
+}
@@ -0,0 +1,72 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type codeError struct{}
+
+func (codeError) Error() string { return "quota exceeded" }
+func (codeError) Code() int     { return -1002 }
+
+func TestBizError(t *testing.T) {
+	errNotFound := RegisterCode(-1001, http.StatusNotFound, "user not found")
+	defer func() {
+		codesLock.Lock()
+		delete(codes, -1001)
+		codesLock.Unlock()
+	}()
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    int
+		wantMessage string
+		// 始终200和使用真实HTTP状态码时的状态码
+		wantStatus     int
+		wantRealStatus int
+	}{
+		{"registered", errNotFound, -1001, "user not found", http.StatusOK, http.StatusNotFound},
+		{"wrapped", fmt.Errorf("find user: %w", errNotFound.WithCause(errors.New("record not found"))), -1001, "user not found", http.StatusOK, http.StatusNotFound},
+		{"explicit status", ErrTimeout.WithHttpStatus(http.StatusGatewayTimeout), STATUS_ERROR_TIMEOUT, "request timeout", http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+		{"framework code", NewBizError(STATUS_NO_AUTHENTICATION, ""), STATUS_NO_AUTHENTICATION, "authentication required", http.StatusOK, http.StatusUnauthorized},
+		{"unregistered code", NewBizError(-1003, "oops"), -1003, "oops", http.StatusOK, http.StatusInternalServerError},
+		{"code error", codeError{}, -1002, "quota exceeded", http.StatusOK, http.StatusInternalServerError},
+		{"plain error", errors.New("boom"), STATUS_ERROR_COMMON, "boom", http.StatusOK, http.StatusInternalServerError},
+	}
+	defer SetHttpStatus(false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bizError := AsBizError(tt.err)
+			if bizError.Code != tt.wantCode || bizError.Message != tt.wantMessage {
+				t.Errorf("AsBizError = %d %q, want %d %q", bizError.Code, bizError.Message, tt.wantCode, tt.wantMessage)
+			}
+			SetHttpStatus(false)
+			if status := HttpStatus(context.Background(), bizError); status != tt.wantStatus {
+				t.Errorf("HttpStatus = %d, want %d", status, tt.wantStatus)
+			}
+			SetHttpStatus(true)
+			if status := HttpStatus(context.Background(), bizError); status != tt.wantRealStatus {
+				t.Errorf("HttpStatus with real status = %d, want %d", status, tt.wantRealStatus)
+			}
+		})
+	}
+
+	// 请求级设置覆盖全局设置
+	if status := HttpStatus(WithHttpStatusMode(context.Background(), false), errNotFound); status != http.StatusOK {
+		t.Errorf("HttpStatus with request mode = %d, want 200", status)
+	}
+
+	if !errors.Is(fmt.Errorf("wrap: %w", errNotFound.WithMessage("user 7 not found")), errNotFound) {
+		t.Error("errors.Is should match by code")
+	}
+	// 响应解析后仍可按状态码判断
+	err := ErrorWithData("user not found", -1001, map[string]any{"id": 7}).Error()
+	if !errors.Is(err, errNotFound) || AsBizError(err).Details == nil {
+		t.Errorf("Response.Error() = %#v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package httpx
+
+import "strings"
+
+const (
+	DEFAULT_PAGE_SIZE = 20
+	MAX_PAGE_SIZE     = 100
+)
+
+// PageRequest 分页参数，嵌入请求结构体后由rest.Handle从查询参数绑定，如?page=2&size=50&sort=-createdAt,id。
+// 超出范围的page和size按默认值和MAX_PAGE_SIZE修正，无需校验。
+//
+//	type ListUserReq struct {
+//		httpx.PageRequest
+//		Name string `form:"name"`
+//	}
+type PageRequest struct {
+	Page int `form:"page" json:"page"`
+	Size int `form:"size" json:"size"`
+	// Sort 逗号分隔的排序字段，-前缀表示降序，字段需在db.Paginate的白名单中
+	Sort string `form:"sort" json:"sort,omitempty"`
+	// Cursor 游标分页时上一页返回的nextCursor，首页为空
+	Cursor string `form:"cursor" json:"cursor,omitempty"`
+}
+
+// SortField 排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// PageNum 从1开始的页码
+func (p PageRequest) PageNum() int {
+	if p.Page < 1 {
+		return 1
+	}
+	return p.Page
+}
+
+// Limit 每页数量，未设置时为DEFAULT_PAGE_SIZE，最大MAX_PAGE_SIZE
+func (p PageRequest) Limit() int {
+	if p.Size < 1 {
+		return DEFAULT_PAGE_SIZE
+	}
+	if p.Size > MAX_PAGE_SIZE {
+		return MAX_PAGE_SIZE
+	}
+	return p.Size
+}
+
+func (p PageRequest) Offset() int {
+	return (p.PageNum() - 1) * p.Limit()
+}
+
+// SortFields 解析Sort，忽略空字段
+func (p PageRequest) SortFields() []SortField {
+	var fields []SortField
+	for _, s := range strings.Split(p.Sort, ",") {
+		s = strings.TrimSpace(s)
+		field := SortField{Field: strings.TrimLeft(s, "+-"), Desc: strings.HasPrefix(s, "-")}
+		if field.Field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// PageResult 分页结果，游标分页时Total为-1，NextCursor为空表示没有下一页
+type PageResult[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// NewPageResult items为nil时返回空数组
+func NewPageResult[T any](items []T, total int64, page PageRequest) PageResult[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return PageResult[T]{Items: items, Total: total, Page: page.PageNum(), Size: page.Limit()}
+}
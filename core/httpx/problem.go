@@ -0,0 +1,181 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const (
+	MIME_JSON         = "application/json"
+	MIME_PROBLEM_JSON = "application/problem+json"
+)
+
+// StatusResponse 可选接口，实现时错误响应始终使用BizError的HTTP状态码，不受http.httpstatus影响
+type StatusResponse interface {
+	Response
+	SetStatus(status int)
+	ContentType() string
+}
+
+// ProblemResponse RFC 7807格式：成功时直接返回data，错误时返回application/problem+json，
+// code和details作为扩展字段。通过middleware.ResponseFormat按路由组选用，使用rest.Group.ResponseFormat时OpenAPI文档按此格式生成。
+//
+//	open := engine.Group("/open", middleware.ResponseFormat(&httpx.ProblemResponse{}))
+type ProblemResponse struct {
+	// TypeBase 非空时type为TypeBase+code，如https://errors.example.com/-1001，否则为about:blank
+	TypeBase string `json:"-"`
+
+	Type    string `json:"type,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+	Code    int    `json:"code"`
+	Details any    `json:"details,omitempty"`
+
+	data any
+}
+
+func (r *ProblemResponse) Create(data interface{}, code int, message string) Response {
+	p := &ProblemResponse{TypeBase: r.TypeBase, Code: code}
+	if code == STATUS_SUCCESS {
+		p.data = data
+		return p
+	}
+	p.Type = "about:blank"
+	if r.TypeBase != "" {
+		p.Type = r.TypeBase + strconv.Itoa(code)
+	}
+	p.Detail = message
+	p.Details = data
+	p.SetStatus(NewBizError(code, "").Status())
+	return p
+}
+
+// SetStatus 设置HTTP状态码，title为状态码注册的提示
+func (r *ProblemResponse) SetStatus(status int) {
+	r.Status = status
+	if info, ok := LookupCode(r.Code); ok && info.Message != "" {
+		r.Title = info.Message
+	} else {
+		r.Title = http.StatusText(status)
+	}
+}
+
+func (r *ProblemResponse) ContentType() string {
+	if r.isSuccess() {
+		return MIME_JSON + "; charset=utf-8"
+	}
+	return MIME_PROBLEM_JSON
+}
+
+func (r *ProblemResponse) GetData() interface{} {
+	if r.isSuccess() {
+		return r.data
+	}
+	return r.Details
+}
+
+func (r *ProblemResponse) Error() error {
+	if r.isSuccess() {
+		return nil
+	}
+	code := r.Code
+	if code == STATUS_SUCCESS {
+		code = STATUS_ERROR_COMMON
+	}
+	message := r.Detail
+	if message == "" {
+		message = r.Title
+	}
+	return &BizError{Code: code, Message: message, Details: r.Details}
+}
+
+// MarshalJSON 成功时只输出data，不转义HTML字符
+func (r *ProblemResponse) MarshalJSON() ([]byte, error) {
+	type problem ProblemResponse
+	var v any = (*problem)(r)
+	if r.isSuccess() {
+		v = r.data
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (r *ProblemResponse) isSuccess() bool {
+	return r.Code == STATUS_SUCCESS && r.Status < http.StatusBadRequest
+}
+
+type responseKey struct{}
+
+// WithResponse 设置当前请求的响应格式，参见middleware.ResponseFormat
+func WithResponse(ctx context.Context, response Response) context.Context {
+	return context.WithValue(ctx, responseKey{}, response)
+}
+
+// ResponseFromContext 当前请求的响应格式，未设置时为SetResponse设置的全局格式
+func ResponseFromContext(ctx context.Context) Response {
+	if response, ok := ctx.Value(responseKey{}).(Response); ok {
+		return response
+	}
+	return responseGenerator
+}
+
+// DecodeResponse 按被调用服务的响应格式format解析响应体，返回data，format为nil时使用SetResponse设置的全局格式。
+// 业务错误时返回*BizError，其HttpStatus为0，再次返回给调用方时按本服务的配置决定HTTP状态码。
+// application/problem+json始终为错误；format为ProblemResponse时其余响应体为成功时的data；
+// 其他格式将响应体解码为format，HTTP状态码>=400且响应体无法解码时为错误。
+func DecodeResponse(format Response, status int, contentType string, body []byte) (interface{}, error) {
+	if format == nil {
+		format = responseGenerator
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == MIME_PROBLEM_JSON {
+		problem := &ProblemResponse{}
+		if err := json.Unmarshal(body, problem); err != nil {
+			return nil, err
+		}
+		if problem.Status == 0 {
+			problem.Status = status
+		}
+		if err := problem.Error(); err != nil {
+			return nil, err
+		}
+		return nil, &BizError{Code: STATUS_ERROR_COMMON, Message: problem.Title}
+	}
+	if _, ok := format.(*ProblemResponse); ok {
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, statusError(status, err)
+		}
+		if status >= http.StatusBadRequest {
+			return nil, &BizError{Code: STATUS_ERROR_COMMON, Message: http.StatusText(status), Details: data}
+		}
+		return data, nil
+	}
+	resp := format.Create(nil, STATUS_SUCCESS, "")
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, statusError(status, err)
+	}
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+	return resp.GetData(), nil
+}
+
+// statusError 响应体无法解析时，HTTP错误状态码优先于解析错误
+func statusError(status int, err error) error {
+	if status >= http.StatusBadRequest {
+		return &BizError{Code: STATUS_ERROR_COMMON, Message: fmt.Sprintf("%d %s", status, http.StatusText(status))}
+	}
+	return err
+}
@@ -2,7 +2,6 @@ package httpx
 
 import (
 	"database/sql/driver"
-	"errors"
 	"fmt"
 	"time"
 )
@@ -12,6 +11,10 @@ const (
 	STATUS_SUCCESS           = 0
 	STATUS_ERROR_COMMON      = -1
 	STATUS_ERROR_LIMIT       = -899
+	STATUS_ERROR_TOO_LARGE   = -898
+	STATUS_ERROR_TIMEOUT     = -897
+	STATUS_ERROR_PARAM       = -896
+	STATUS_ERROR_UNAVAILABLE = -895
 	STATUS_NO_AUTHENTICATION = -999
 	STATUS_NO_AUTHORIZATION  = -989
 )
@@ -40,11 +43,12 @@ func (r *DefaultResponse) GetData() interface{} {
 	return r.Data
 }
 
+// Error 非成功时返回*BizError，data作为Details
 func (r *DefaultResponse) Error() error {
 	if r.Code == 0 {
 		return nil
 	}
-	return errors.New(r.Message)
+	return &BizError{Code: r.Code, Message: r.Message, Details: r.Data}
 }
 
 func SetResponse(response Response) {
@@ -65,6 +69,11 @@ func ErrorWithCode(msg string, code int) Response {
 	return responseGenerator.Create(nil, code, msg)
 }
 
+// ErrorWithData 错误响应附带数据，如参数校验的字段错误
+func ErrorWithData(msg string, code int, data interface{}) Response {
+	return responseGenerator.Create(data, code, msg)
+}
+
 type Time time.Time
 type Date time.Time
 
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kappere/go-rest/core/metric"
+)
+
+// 异步写入默认参数
+const (
+	DEFAULT_ASYNC_QUEUE_SIZE     = 8192
+	DEFAULT_ASYNC_FLUSH_INTERVAL = time.Second
+	asyncBufferSize              = 256 << 10
+)
+
+var logDropped = metric.NewCounterVec("log_dropped_lines_total", "Number of log lines dropped because the async queue was full.")
+
+// AsyncWriter 异步写入，Write将日志放入有界队列后立即返回，由后台goroutine批量写入。
+// 队列满时按Block丢弃或阻塞，定时和Flush时刷新缓冲，Close后退化为同步写入。
+type AsyncWriter struct {
+	writer io.Writer
+	block  bool
+
+	lock    sync.RWMutex
+	closed  bool
+	queue   chan []byte
+	flush   chan chan struct{}
+	done    chan struct{}
+	dropped atomic.Int64
+	// 串行化Close后的同步写入
+	syncLock sync.Mutex
+}
+
+// NewAsyncWriter 创建异步writer，queueSize为队列行数，block为true时队列满阻塞写入方，否则丢弃，
+// flushInterval为定时刷新间隔
+func NewAsyncWriter(w io.Writer, queueSize int, block bool, flushInterval time.Duration) *AsyncWriter {
+	if queueSize <= 0 {
+		queueSize = DEFAULT_ASYNC_QUEUE_SIZE
+	}
+	if flushInterval <= 0 {
+		flushInterval = DEFAULT_ASYNC_FLUSH_INTERVAL
+	}
+	a := &AsyncWriter{
+		writer: w,
+		block:  block,
+		queue:  make(chan []byte, queueSize),
+		flush:  make(chan chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.run(flushInterval)
+	return a
+}
+
+// Write 复制p后入队，slog等调用方会复用p的缓冲
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.lock.RLock()
+	if a.closed {
+		a.lock.RUnlock()
+		a.syncLock.Lock()
+		defer a.syncLock.Unlock()
+		return a.writer.Write(p)
+	}
+	defer a.lock.RUnlock()
+	line := make([]byte, len(p))
+	copy(line, p)
+	if a.block {
+		a.queue <- line
+		return len(p), nil
+	}
+	select {
+	case a.queue <- line:
+	default:
+		a.dropped.Add(1)
+		logDropped.Inc()
+	}
+	return len(p), nil
+}
+
+// Flush 写入队列中已有的日志并刷新缓冲
+func (a *AsyncWriter) Flush() {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if a.closed {
+		return
+	}
+	reply := make(chan struct{})
+	a.flush <- reply
+	<-reply
+}
+
+// Close 写入剩余日志并停止后台goroutine，不关闭底层writer
+func (a *AsyncWriter) Close() error {
+	a.lock.Lock()
+	if a.closed {
+		a.lock.Unlock()
+		return nil
+	}
+	a.closed = true
+	// 持有写锁时没有Write在入队，关闭队列后后台goroutine写完剩余日志退出
+	close(a.queue)
+	a.lock.Unlock()
+	<-a.done
+	return nil
+}
+
+// Dropped 队列满时丢弃的日志行数
+func (a *AsyncWriter) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+func (a *AsyncWriter) run(flushInterval time.Duration) {
+	defer close(a.done)
+	buf := bufio.NewWriterSize(a.writer, asyncBufferSize)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-a.queue:
+			if !ok {
+				buf.Flush()
+				return
+			}
+			buf.Write(line)
+		case <-ticker.C:
+			buf.Flush()
+		case reply := <-a.flush:
+			a.drain(buf)
+			buf.Flush()
+			close(reply)
+		}
+	}
+}
+
+// drain 写入队列中已有的日志，不等待新日志
+func (a *AsyncWriter) drain(buf *bufio.Writer) {
+	for {
+		select {
+		case line := <-a.queue:
+			buf.Write(line)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer 后台goroutine写入，测试goroutine读取
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+// blockingWriter 放行前阻塞写入，用于填满队列
+type blockingWriter struct {
+	release chan struct{}
+	lockedBuffer
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	<-b.release
+	return b.lockedBuffer.Write(p)
+}
+
+func TestAsyncWriter(t *testing.T) {
+	var out lockedBuffer
+	w := NewAsyncWriter(&out, 16, true, time.Hour)
+	buf := []byte("line 1\n")
+	w.Write(buf)
+	// 写入后修改调用方缓冲，不影响已入队的日志
+	copy(buf, "LINE X\n")
+	w.Write([]byte("line 2\n"))
+	if out.String() != "" {
+		t.Errorf("written before flush: %q", out.String())
+	}
+	w.Flush()
+	if got := out.String(); got != "line 1\nline 2\n" {
+		t.Errorf("after flush = %q", got)
+	}
+	w.Write([]byte("line 3\n"))
+	w.Close()
+	// Close后同步写入
+	w.Write([]byte("line 4\n"))
+	if got := out.String(); got != "line 1\nline 2\nline 3\nline 4\n" {
+		t.Errorf("after close = %q", got)
+	}
+}
+
+func TestAsyncWriterFull(t *testing.T) {
+	tests := []struct {
+		name        string
+		block       bool
+		wantDropped bool
+	}{
+		{"drop", false, true},
+		{"block", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &blockingWriter{release: make(chan struct{})}
+			w := NewAsyncWriter(out, 2, tt.block, time.Millisecond)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < 100; i++ {
+					w.Write([]byte(strings.Repeat("x", asyncBufferSize/10)))
+				}
+			}()
+			select {
+			case <-done:
+				if tt.block {
+					t.Fatal("write should block when queue is full")
+				}
+			case <-time.After(100 * time.Millisecond):
+				if !tt.block {
+					t.Fatal("write should not block when queue is full")
+				}
+			}
+			close(out.release)
+			<-done
+			w.Close()
+			if got := w.Dropped() > 0; got != tt.wantDropped {
+				t.Errorf("dropped = %d, want dropped %v", w.Dropped(), tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestAsyncWriterTimerFlush(t *testing.T) {
+	var out lockedBuffer
+	w := NewAsyncWriter(&out, 16, false, 10*time.Millisecond)
+	defer w.Close()
+	w.Write([]byte("hello\n"))
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("timer flush = %q", out.String())
+	}
+}
+
+// 对比原先O_SYNC文件同步写入和异步写入的单行耗时：
+//
+//	go test -bench=Writer -benchmem ./core/logger
+func BenchmarkSyncFileWriter(b *testing.B) {
+	file, err := os.OpenFile(filepath.Join(b.TempDir(), "sync.log"), os.O_WRONLY|os.O_CREATE|os.O_SYNC|os.O_APPEND, 0644)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+	benchmarkWriter(b, io.MultiWriter(io.Discard, file))
+}
+
+func BenchmarkAsyncFileWriter(b *testing.B) {
+	w := &RotateWriter{Dir: b.TempDir(), Prefix: "async"}
+	defer w.Close()
+	async := NewAsyncWriter(io.MultiWriter(io.Discard, w), 0, true, 0)
+	defer async.Close()
+	benchmarkWriter(b, async)
+}
+
+func BenchmarkModuleLogger(b *testing.B) {
+	old := output.Load()
+	defer output.Store(old)
+	var h slog.Handler = slog.NewTextHandler(io.Discard, nil)
+	output.Store(&h)
+	log := Module(MODULE_REST).With("component", "access")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("HTTP", "status", 200, "method", "GET", "path", "/api/user", "latency", time.Millisecond)
+		}
+	})
+}
+
+func benchmarkWriter(b *testing.B, w io.Writer) {
+	log := slog.New(slog.NewTextHandler(w, nil))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("HTTP", "status", 200, "method", "GET", "path", "/api/user", "latency", time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kappere/go-rest/core/trace"
+)
+
+// 日志中自动附加的上下文字段
+const (
+	REQUEST_ID_KEY = "request_id"
+	TRACE_ID_KEY   = "trace_id"
+	SUBJECT_KEY    = "subject"
+	CLIENT_ID_KEY  = "client_id"
+)
+
+type contextFields struct {
+	requestID string
+	subject   string
+	clientID  string
+}
+
+type contextFieldsKey struct{}
+
+func fieldsFromContext(ctx context.Context) contextFields {
+	if ctx == nil {
+		return contextFields{}
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).(contextFields)
+	return fields
+}
+
+func withFields(ctx context.Context, update func(fields *contextFields)) context.Context {
+	fields := fieldsFromContext(ctx)
+	update(&fields)
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// WithRequestID 记录请求ID，slog.XxxContext(ctx, ...)输出时附加request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return withFields(ctx, func(fields *contextFields) { fields.requestID = requestID })
+}
+
+// WithSubject 记录JWT subject，输出时附加subject
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return withFields(ctx, func(fields *contextFields) { fields.subject = subject })
+}
+
+// WithClientID 记录OAuth2 client id，输出时附加client_id
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return withFields(ctx, func(fields *contextFields) { fields.clientID = clientID })
+}
+
+// RequestIDFromContext 当前请求ID，没有时为空
+func RequestIDFromContext(ctx context.Context) string {
+	return fieldsFromContext(ctx).requestID
+}
+
+// contextHandler 从context中读取请求ID、trace ID、JWT subject和OAuth2 client id附加到日志
+type contextHandler struct {
+	slog.Handler
+}
+
+func newContextHandler(handler slog.Handler) *contextHandler {
+	return &contextHandler{Handler: handler}
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := fieldsFromContext(ctx)
+	if fields.requestID != "" {
+		r.AddAttrs(slog.String(REQUEST_ID_KEY, fields.requestID))
+	}
+	if traceID := trace.TraceIDFromContext(ctx); traceID != "" {
+		r.AddAttrs(slog.String(TRACE_ID_KEY, traceID))
+	}
+	if fields.subject != "" {
+		r.AddAttrs(slog.String(SUBJECT_KEY, fields.subject))
+	}
+	if fields.clientID != "" {
+		r.AddAttrs(slog.String(CLIENT_ID_KEY, fields.clientID))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newContextHandler(h.Handler.WithAttrs(attrs))
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return newContextHandler(h.Handler.WithGroup(name))
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kappere/go-rest/core/trace"
+)
+
+func TestContextHandler(t *testing.T) {
+	ctx, _ := trace.StartRemote(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "GET /", trace.KIND_SERVER)
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithSubject(ctx, "user-1")
+	ctx = WithClientID(ctx, "client-1")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "all fields",
+			ctx:  ctx,
+			want: []string{"request_id=req-1", "trace_id=4bf92f3577b34da6a3ce929d0e0e4736", "subject=user-1", "client_id=client-1"},
+		},
+		{
+			name:    "request id only",
+			ctx:     WithRequestID(context.Background(), "req-2"),
+			want:    []string{"request_id=req-2"},
+			notWant: []string{"trace_id", "subject", "client_id"},
+		},
+		{
+			name:    "empty context",
+			ctx:     context.Background(),
+			notWant: []string{"request_id", "trace_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(newContextHandler(slog.NewTextHandler(&buf, nil))).With("module", "test")
+			log.InfoContext(tt.ctx, "hello")
+			out := buf.String()
+			for _, want := range append(tt.want, "module=test") {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q: %s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output should not contain %q: %s", notWant, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// 框架内置模块
+const (
+	MODULE_REST = "rest"
+	MODULE_RPC  = "rpc"
+	MODULE_DB   = "db"
+	MODULE_TASK = "task"
+)
+
+// MODULE_KEY 模块日志附加的属性名
+const MODULE_KEY = "module"
+
+// 全局日志级别
+var level = new(slog.LevelVar)
+
+var (
+	moduleLock   sync.RWMutex
+	moduleLevels = make(map[string]*slog.LevelVar)
+)
+
+// output 当前输出handler，不做级别过滤，由InitLogger替换
+var output atomic.Pointer[slog.Handler]
+
+func init() {
+	var h slog.Handler = newContextHandler(slog.NewTextHandler(defaultWriter, &slog.HandlerOptions{Level: slog.Level(-8)}))
+	output.Store(&h)
+}
+
+func parseLevel(l string) (slog.Level, error) {
+	if l == "" {
+		l = "info"
+	}
+	var lv slog.Level
+	err := lv.UnmarshalText([]byte(strings.ToUpper(l)))
+	return lv, err
+}
+
+// SetLevel 设置全局日志级别：debug/info/warn/error，支持运行时修改
+func SetLevel(l string) error {
+	lv, err := parseLevel(l)
+	if err != nil {
+		return err
+	}
+	level.Set(lv)
+	return nil
+}
+
+// SetModuleLevel 设置模块日志级别，l为空时取消覆盖，使用全局级别
+func SetModuleLevel(module string, l string) error {
+	moduleLock.Lock()
+	defer moduleLock.Unlock()
+	if l == "" {
+		delete(moduleLevels, module)
+		return nil
+	}
+	lv, err := parseLevel(l)
+	if err != nil {
+		return err
+	}
+	if v, ok := moduleLevels[module]; ok {
+		v.Set(lv)
+		return nil
+	}
+	v := new(slog.LevelVar)
+	v.Set(lv)
+	moduleLevels[module] = v
+	return nil
+}
+
+// SetModuleLevels 替换所有模块日志级别，返回第一个无效的级别错误
+func SetModuleLevels(levels map[string]string) error {
+	moduleLock.Lock()
+	for module := range moduleLevels {
+		if _, ok := levels[module]; !ok {
+			delete(moduleLevels, module)
+		}
+	}
+	moduleLock.Unlock()
+	var firstErr error
+	for module, l := range levels {
+		if err := SetModuleLevel(module, l); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Levels 当前全局级别和各模块级别
+func Levels() (global string, modules map[string]string) {
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	modules = make(map[string]string, len(moduleLevels))
+	for module, v := range moduleLevels {
+		modules[module] = strings.ToLower(v.Level().String())
+	}
+	return strings.ToLower(level.Level().String()), modules
+}
+
+func moduleLevel(module string) slog.Level {
+	if module != "" {
+		moduleLock.RLock()
+		v, ok := moduleLevels[module]
+		moduleLock.RUnlock()
+		if ok {
+			return v.Level()
+		}
+	}
+	return level.Level()
+}
+
+// Module 返回模块日志，附加module属性，级别由SetModuleLevel控制，未设置时使用全局级别。
+// 可在包初始化时调用，InitLogger之后自动使用新的输出。
+func Module(module string) *slog.Logger {
+	return slog.New(&leveledHandler{module: module}).With(MODULE_KEY, module)
+}
+
+// leveledHandler 按全局或模块级别过滤后交给当前输出handler
+type leveledHandler struct {
+	module string
+	// WithAttrs/WithGroup作用于当前输出handler，以支持InitLogger替换输出
+	ops []func(slog.Handler) slog.Handler
+	// 由ops构建的handler及其所基于的输出，输出替换后重新构建
+	built atomic.Pointer[builtHandler]
+}
+
+type builtHandler struct {
+	output  *slog.Handler
+	handler slog.Handler
+}
+
+func (h *leveledHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= moduleLevel(h.module)
+}
+
+func (h *leveledHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *leveledHandler) handler() slog.Handler {
+	out := output.Load()
+	if b := h.built.Load(); b != nil && b.output == out {
+		return b.handler
+	}
+	handler := *out
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	h.built.Store(&builtHandler{output: out, handler: handler})
+	return handler
+}
+
+func (h *leveledHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *leveledHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *leveledHandler) with(op func(slog.Handler) slog.Handler) *leveledHandler {
+	ops := append(append([]func(slog.Handler) slog.Handler{}, h.ops...), op)
+	return &leveledHandler{module: h.module, ops: ops}
+}
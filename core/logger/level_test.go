@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestModuleLevel(t *testing.T) {
+	var buf bytes.Buffer
+	var h slog.Handler = slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.Level(-8)})
+	prev := output.Swap(&h)
+	defer func() {
+		output.Store(prev)
+		SetLevel("info")
+		SetModuleLevels(nil)
+	}()
+	SetLevel("warn")
+	if err := SetModuleLevels(map[string]string{MODULE_DB: "debug", MODULE_RPC: "error"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		module string
+		level  slog.Level
+		want   bool
+	}{
+		{MODULE_DB, slog.LevelDebug, true},
+		{MODULE_RPC, slog.LevelWarn, false},
+		{MODULE_RPC, slog.LevelError, true},
+		{MODULE_REST, slog.LevelInfo, false},
+		{MODULE_REST, slog.LevelWarn, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.module+"-"+tt.level.String(), func(t *testing.T) {
+			buf.Reset()
+			Module(tt.module).Log(context.Background(), tt.level, "hello", "status", 200)
+			if got := buf.Len() > 0; got != tt.want {
+				t.Fatalf("logged = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			if record[MODULE_KEY] != tt.module || record["status"] != float64(200) {
+				t.Errorf("record = %v", record)
+			}
+		})
+	}
+
+	if err := SetModuleLevel(MODULE_DB, "verbose"); err == nil {
+		t.Error("SetModuleLevel(verbose) expect error")
+	}
+	SetModuleLevel(MODULE_DB, "")
+	if _, modules := Levels(); modules[MODULE_DB] != "" || modules[MODULE_RPC] != "error" {
+		t.Errorf("Levels() modules = %v", modules)
+	}
+}
+
+func TestModuleOutputSwap(t *testing.T) {
+	var first, second bytes.Buffer
+	var h1 slog.Handler = slog.NewJSONHandler(&first, nil)
+	var h2 slog.Handler = slog.NewJSONHandler(&second, nil)
+	prev := output.Swap(&h1)
+	defer output.Store(prev)
+
+	// 先于替换输出创建的logger在替换后使用新的输出，属性保持不变
+	log := Module(MODULE_REST).With("component", "access")
+	log.Info("first")
+	output.Store(&h2)
+	log.Info("second")
+	if !bytes.Contains(first.Bytes(), []byte(`"msg":"first"`)) || bytes.Contains(first.Bytes(), []byte("second")) {
+		t.Errorf("first output = %s", first.String())
+	}
+	if !bytes.Contains(second.Bytes(), []byte(`"msg":"second","module":"rest","component":"access"`)) {
+		t.Errorf("second output = %s", second.String())
+	}
+}
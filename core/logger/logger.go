@@ -1,37 +1,123 @@
 package logger
 
 import (
-	"fmt"
 	"io"
-	"log"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/kappere/go-rest/core/config/conf"
-	"github.com/kappere/go-rest/core/task"
+)
+
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+)
+
+// switchWriter 可切换输出的writer，切换时持有锁，避免关闭旧文件时仍有写入
+type switchWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.w.Write(p)
+}
+
+// swap 切换输出，返回旧的writer
+func (s *switchWriter) swap(w io.Writer) io.Writer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	prev := s.w
+	s.w = w
+	return prev
+}
+
+var defaultWriter = &switchWriter{w: os.Stdout}
+
+// 当前日志文件和异步writer，Stdout模式下fileWriter为nil，未开启异步时asyncWriter为nil
+var (
+	fileLock    sync.Mutex
+	fileWriter  *RotateWriter
+	asyncWriter *AsyncWriter
 )
 
 func InitLogger(logConfig conf.LogConfig, appName string) {
-	var prevLogFile *os.File = nil
-	os.MkdirAll(logConfig.Path, os.ModeDir)
-	archiveLogFunc := func() {
-		now := time.Now()
-		filename := fmt.Sprintf(logConfig.Path+"/%s_%04d%02d%02d.log", appName, now.Year(), now.Month(), now.Day())
-		logFile, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_SYNC|os.O_APPEND, 0755)
-		if err != nil {
-			slog.Error("Create log file failed", "error", err)
+	if err := SetLevel(logConfig.Level); err != nil {
+		slog.Error("Invalid log level, use info.", "level", logConfig.Level)
+	}
+	if err := SetModuleLevels(logConfig.Modules); err != nil {
+		slog.Error("Invalid module log level.", "error", err)
+	}
+	// 级别由leveledHandler过滤，输出handler不再过滤
+	options := &slog.HandlerOptions{Level: slog.Level(-8)}
+	var handler slog.Handler
+	if strings.ToLower(logConfig.Format) == FORMAT_JSON {
+		handler = slog.NewJSONHandler(defaultWriter, options)
+	} else {
+		handler = slog.NewTextHandler(defaultWriter, options)
+	}
+	// 使用slog.XxxContext(ctx, ...)时自动附加context中的请求ID、trace ID等
+	handler = newContextHandler(handler)
+	output.Store(&handler)
+	// slog作为默认日志，标准库log的输出也经由该handler
+	slog.SetDefault(slog.New(&leveledHandler{}))
+
+	// 关闭上一次InitLogger打开的文件，切换输出后再关闭，避免写入已关闭的文件
+	var next io.Writer = os.Stdout
+	var rotate *RotateWriter
+	if !logConfig.Stdout {
+		rotate = &RotateWriter{
+			Dir:        logConfig.Path,
+			Prefix:     appName,
+			MaxSize:    int64(logConfig.MaxSize) << 20,
+			MaxAge:     time.Duration(logConfig.MaxAge) * 24 * time.Hour,
+			MaxBackups: logConfig.MaxBackups,
+			Compress:   logConfig.Compress,
 		}
-		newWriter := io.MultiWriter(os.Stdout, logFile)
+		next = io.MultiWriter(os.Stdout, rotate)
+	}
+	var async *AsyncWriter
+	if logConfig.Async.Enable {
+		async = NewAsyncWriter(next, logConfig.Async.QueueSize, logConfig.Async.Block,
+			time.Duration(logConfig.Async.FlushInterval)*time.Millisecond)
+		next = async
+	}
+	defaultWriter.swap(next)
+	fileLock.Lock()
+	prevAsync, prevFile := asyncWriter, fileWriter
+	asyncWriter, fileWriter = async, rotate
+	fileLock.Unlock()
+	// 先写完异步队列再关闭文件
+	if prevAsync != nil {
+		prevAsync.Close()
+	}
+	if prevFile != nil {
+		prevFile.Close()
+	}
+}
 
-		// 重定向日志到新文件
-		log.SetOutput(newWriter)
+// Flush 写入异步队列中的日志，未开启异步写入时直接返回
+func Flush() {
+	fileLock.Lock()
+	async := asyncWriter
+	fileLock.Unlock()
+	if async != nil {
+		async.Flush()
+	}
+}
 
-		if prevLogFile != nil {
-			prevLogFile.Close()
-		}
-		prevLogFile = logFile
+// Dropped 异步队列满时丢弃的日志行数
+func Dropped() int64 {
+	fileLock.Lock()
+	async := asyncWriter
+	fileLock.Unlock()
+	if async == nil {
+		return 0
 	}
-	archiveLogFunc()
-	task.NewTaskFunc("0 0 0 * * ?", "LogArchive", archiveLogFunc)
+	return async.Dropped()
 }
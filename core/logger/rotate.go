@@ -0,0 +1,296 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const compressSuffix = ".gz"
+
+// RotateWriter 按天和大小切分的日志文件，文件名为<prefix>_YYYYMMDD.log，
+// 同一天超过MaxSize时依次重命名为<prefix>_YYYYMMDD.1.log、<prefix>_YYYYMMDD.2.log...
+// 切分在写锁内完成，不会向已关闭的文件写入；压缩和清理在后台goroutine中串行执行。
+type RotateWriter struct {
+	// Dir 日志目录
+	Dir string
+	// Prefix 文件名前缀，一般为应用名
+	Prefix string
+	// MaxSize 单个文件最大字节数，0表示不按大小切分
+	MaxSize int64
+	// MaxAge 历史文件保留时长，0表示不按时间清理
+	MaxAge time.Duration
+	// MaxBackups 历史文件保留个数，0表示不按个数清理
+	MaxBackups int
+	// Compress 历史文件gzip压缩
+	Compress bool
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+	day  string
+	now  func() time.Time
+
+	millOnce sync.Once
+	millCh   chan struct{}
+	millDone chan struct{}
+}
+
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	day := w.currentTime().Format("20060102")
+	if w.file == nil {
+		if err := w.open(day); err != nil {
+			return 0, err
+		}
+		// 清理启动前遗留的历史文件
+		w.mill()
+	} else if day != w.day {
+		if err := w.rotate(day, false); err != nil {
+			return 0, err
+		}
+	} else if w.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.MaxSize {
+		if err := w.rotate(day, true); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即切分当前文件
+func (w *RotateWriter) Rotate() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.rotate(w.currentTime().Format("20060102"), true)
+}
+
+// Close 关闭当前文件，等待后台压缩和清理完成
+func (w *RotateWriter) Close() error {
+	w.lock.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	millCh, millDone := w.millCh, w.millDone
+	w.millCh = nil
+	w.lock.Unlock()
+	if millCh != nil {
+		close(millCh)
+		<-millDone
+	}
+	return err
+}
+
+func (w *RotateWriter) currentTime() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+func (w *RotateWriter) filename(day string) string {
+	return filepath.Join(w.Dir, w.Prefix+"_"+day+".log")
+}
+
+// open 打开当天文件，追加写入
+func (w *RotateWriter) open(day string) error {
+	if err := os.MkdirAll(w.Dir, 0755); err != nil {
+		return err
+	}
+	name := w.filename(day)
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size, w.day = file, info.Size(), day
+	return nil
+}
+
+// rotate 关闭当前文件，bySize时将其重命名为下一个序号，然后打开新文件
+func (w *RotateWriter) rotate(day string, bySize bool) error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+		if bySize {
+			name := w.filename(w.day)
+			if err := os.Rename(name, w.backupName(w.day)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	if err := w.open(day); err != nil {
+		return err
+	}
+	w.mill()
+	return nil
+}
+
+// backupName 当天已有最大序号加1，清理过的序号不再复用，保证序号越大越新
+func (w *RotateWriter) backupName(day string) string {
+	prefix := w.Prefix + "_" + day + "."
+	next := 1
+	entries, _ := os.ReadDir(w.Dir)
+	for _, e := range entries {
+		rest, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSuffix(rest, compressSuffix)
+		if n, err := strconv.Atoi(strings.TrimSuffix(rest, ".log")); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return filepath.Join(w.Dir, prefix+strconv.Itoa(next)+".log")
+}
+
+// mill 通知后台压缩和清理，已有待处理的通知时合并
+func (w *RotateWriter) mill() {
+	if w.MaxAge <= 0 && w.MaxBackups <= 0 && !w.Compress {
+		return
+	}
+	w.millOnce.Do(func() {
+		w.millCh = make(chan struct{}, 1)
+		w.millDone = make(chan struct{})
+		go w.millRun(w.millCh, w.millDone)
+	})
+	if w.millCh == nil {
+		return
+	}
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *RotateWriter) millRun(millCh chan struct{}, done chan struct{}) {
+	defer close(done)
+	for range millCh {
+		if err := w.millRunOnce(); err != nil {
+			slog.Error("Clean log files failed.", "error", err)
+		}
+	}
+}
+
+type logFile struct {
+	name    string
+	modTime time.Time
+}
+
+// millRunOnce 压缩历史文件，删除超出个数或过期的文件
+func (w *RotateWriter) millRunOnce() error {
+	w.lock.Lock()
+	current := ""
+	if w.file != nil {
+		current = w.file.Name()
+	}
+	cutoff := w.currentTime().Add(-w.MaxAge)
+	w.lock.Unlock()
+
+	files, err := w.backups(current)
+	if err != nil {
+		return err
+	}
+	var remove, compress []logFile
+	for i, f := range files {
+		if (w.MaxBackups > 0 && i >= w.MaxBackups) || (w.MaxAge > 0 && f.modTime.Before(cutoff)) {
+			remove = append(remove, f)
+		} else if w.Compress && !strings.HasSuffix(f.name, compressSuffix) {
+			compress = append(compress, f)
+		}
+	}
+	var errs []error
+	for _, f := range remove {
+		if err := os.Remove(f.name); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	for _, f := range compress {
+		if err := compressFile(f.name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backups 除当前文件外的历史文件，按修改时间从新到旧
+func (w *RotateWriter) backups(current string) ([]logFile, error) {
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []logFile
+	for _, e := range entries {
+		name := e.Name()
+		// 前缀后必须是日期，避免误删前缀相同的其他应用日志
+		rest, ok := strings.CutPrefix(name, w.Prefix+"_")
+		if e.IsDir() || !ok || rest == "" || rest[0] < '0' || rest[0] > '9' {
+			continue
+		}
+		if !strings.HasSuffix(name, ".log") && !strings.HasSuffix(name, ".log"+compressSuffix) {
+			continue
+		}
+		path := filepath.Join(w.Dir, name)
+		if path == current {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFile{name: path, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	return files, nil
+}
+
+// compressFile gzip压缩后删除原文件，保留修改时间以便按时间清理
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(name+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + compressSuffix)
+		return err
+	}
+	os.Chtimes(name+compressSuffix, info.ModTime(), info.ModTime())
+	return os.Remove(name)
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotateWriter(t *testing.T) {
+	day := time.Date(2024, 5, 1, 23, 59, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		writer func() *RotateWriter
+		// 每次写入前的时间偏移
+		writes []time.Duration
+		want   []string
+	}{
+		{
+			name:   "by size",
+			writer: func() *RotateWriter { return &RotateWriter{MaxSize: 10} },
+			writes: []time.Duration{0, 0, 0},
+			want:   []string{"app_20240501.1.log", "app_20240501.2.log", "app_20240501.log"},
+		},
+		{
+			name:   "by day",
+			writer: func() *RotateWriter { return &RotateWriter{} },
+			writes: []time.Duration{0, time.Minute, time.Minute},
+			want:   []string{"app_20240501.log", "app_20240502.log"},
+		},
+		{
+			name:   "max backups",
+			writer: func() *RotateWriter { return &RotateWriter{MaxSize: 10, MaxBackups: 1} },
+			writes: []time.Duration{0, 0, 0, 0},
+			want:   []string{"app_20240501.3.log", "app_20240501.log", "other_20240501.log"},
+		},
+		{
+			name:   "compress",
+			writer: func() *RotateWriter { return &RotateWriter{MaxSize: 10, Compress: true} },
+			writes: []time.Duration{0, 0},
+			want:   []string{"app_20240501.1.log.gz", "app_20240501.log", "other_20240501.log"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// 其他应用的日志不受影响
+			w := tt.writer()
+			if w.MaxBackups > 0 || w.Compress {
+				os.WriteFile(filepath.Join(dir, "other_20240501.log"), []byte("other\n"), 0644)
+			}
+			var now atomic.Int64
+			now.Store(day.UnixNano())
+			w.Dir, w.Prefix, w.now = dir, "app", func() time.Time { return time.Unix(0, now.Load()) }
+			for i, d := range tt.writes {
+				now.Add(int64(d))
+				if _, err := w.Write([]byte("line " + string(rune('0'+i)) + "\n")); err != nil {
+					t.Fatal(err)
+				}
+				// 保证修改时间有序
+				time.Sleep(10 * time.Millisecond)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateWriterCompressContent(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotateWriter{Dir: dir, Prefix: "app", MaxSize: 10, Compress: true}
+	w.Write([]byte("first line\n"))
+	w.Write([]byte("second line\n"))
+	w.Close()
+	day := time.Now().Format("20060102")
+	file, err := os.Open(filepath.Join(dir, "app_"+day+".1.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(gz)
+	if string(data) != "first line\n" {
+		t.Errorf("compressed content = %q", data)
+	}
+}
+
+func TestRotateWriterMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app_20240101.log")
+	os.WriteFile(old, []byte("old\n"), 0644)
+	oldTime := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(old, oldTime, oldTime)
+
+	w := &RotateWriter{Dir: dir, Prefix: "app", MaxAge: 24 * time.Hour}
+	w.Write([]byte("new\n"))
+	w.Close()
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("expired file not removed: %v", err)
+	}
+}
+
+// 并发写入时切分不能丢失或写入已关闭的文件
+func TestRotateWriterConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotateWriter{Dir: dir, Prefix: "app", MaxSize: 1 << 10}
+	line := []byte(strings.Repeat("x", 99) + "\n")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := w.Write(line); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	w.Close()
+	var total int64
+	for _, name := range listDir(t, dir) {
+		info, _ := os.Stat(filepath.Join(dir, name))
+		if info.Size() > 1<<10 {
+			t.Errorf("%s size %d exceeds max size", name, info.Size())
+		}
+		total += info.Size()
+	}
+	if total != int64(8*100*len(line)) {
+		t.Errorf("total size = %d, want %d", total, 8*100*len(line))
+	}
+}
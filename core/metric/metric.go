@@ -0,0 +1,309 @@
+// 指标统计，以Prometheus文本格式输出，不依赖外部服务
+package metric
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 默认延迟分桶，单位秒
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const labelSeparator = "\xff"
+
+// collector 一个指标族
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     []collector
+	registered   = make(map[string]bool)
+)
+
+func register(c collector) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if registered[c.name()] {
+		panic("Duplicate metric: " + c.name())
+	}
+	registered[c.name()] = true
+	registry = append(registry, c)
+}
+
+// WriteTo 按注册顺序输出所有指标
+func WriteTo(w io.Writer) {
+	registryLock.RLock()
+	collectors := append([]collector{}, registry...)
+	registryLock.RUnlock()
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler 输出Prometheus文本格式的http.Handler
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteTo(w)
+	})
+}
+
+// vec 按标签值分组的指标值
+type vec[T any] struct {
+	metricName string
+	help       string
+	labels     []string
+	lock       sync.RWMutex
+	values     map[string]*T
+	newValue   func() *T
+}
+
+func (v *vec[T]) name() string {
+	return v.metricName
+}
+
+func (v *vec[T]) with(labelValues []string) *T {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("Metric %s expects %d label values, got %d", v.metricName, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, labelSeparator)
+	v.lock.RLock()
+	value, ok := v.values[key]
+	v.lock.RUnlock()
+	if ok {
+		return value
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if value, ok = v.values[key]; !ok {
+		value = v.newValue()
+		v.values[key] = value
+	}
+	return value
+}
+
+// each 按标签值排序遍历，保证输出稳定
+func (v *vec[T]) each(f func(labelValues []string, value *T)) {
+	v.lock.RLock()
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	v.lock.RUnlock()
+	sort.Strings(keys)
+	for _, key := range keys {
+		v.lock.RLock()
+		value := v.values[key]
+		v.lock.RUnlock()
+		var labelValues []string
+		if len(v.labels) > 0 {
+			labelValues = strings.Split(key, labelSeparator)
+		}
+		f(labelValues, value)
+	}
+}
+
+func (v *vec[T]) writeHeader(w io.Writer, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.metricName, escapeHelp(v.help), v.metricName, typ)
+}
+
+// CounterVec 只增计数器
+type CounterVec struct {
+	vec[counterValue]
+}
+
+type counterValue struct {
+	lock  sync.Mutex
+	value float64
+}
+
+// NewCounterVec 创建并注册计数器，名称重复时panic
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec[counterValue]{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		values:     make(map[string]*counterValue),
+		newValue:   func() *counterValue { return &counterValue{} },
+	}}
+	register(c)
+	return c
+}
+
+// Inc 计数加1，labelValues与创建时的labels一一对应
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	value := c.with(labelValues)
+	value.lock.Lock()
+	value.value += delta
+	value.lock.Unlock()
+}
+
+// Value 当前计数，用于测试和诊断
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	value := c.with(labelValues)
+	value.lock.Lock()
+	defer value.lock.Unlock()
+	return value.value
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.writeHeader(w, "counter")
+	c.each(func(labelValues []string, value *counterValue) {
+		value.lock.Lock()
+		v := value.value
+		value.lock.Unlock()
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, formatLabels(c.labels, labelValues), formatFloat(v))
+	})
+}
+
+// HistogramVec 直方图，统计延迟等分布
+type HistogramVec struct {
+	vec[histogramValue]
+	buckets []float64
+}
+
+type histogramValue struct {
+	lock   sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec 创建并注册直方图，buckets为nil时使用DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{
+		vec: vec[histogramValue]{
+			metricName: name,
+			help:       help,
+			labels:     labels,
+			values:     make(map[string]*histogramValue),
+			newValue: func() *histogramValue {
+				return &histogramValue{counts: make([]uint64, len(buckets))}
+			},
+		},
+		buckets: buckets,
+	}
+	register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	value := h.with(labelValues)
+	i := sort.SearchFloat64s(h.buckets, v)
+	value.lock.Lock()
+	defer value.lock.Unlock()
+	if i < len(h.buckets) {
+		value.counts[i]++
+	}
+	value.count++
+	value.sum += v
+}
+
+// Count 观测次数，用于测试和诊断
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	value := h.with(labelValues)
+	value.lock.Lock()
+	defer value.lock.Unlock()
+	return value.count
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.writeHeader(w, "histogram")
+	bucketLabels := append(append([]string{}, h.labels...), "le")
+	h.each(func(labelValues []string, value *histogramValue) {
+		value.lock.Lock()
+		counts := append([]uint64{}, value.counts...)
+		count, sum := value.count, value.sum
+		value.lock.Unlock()
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, formatLabels(bucketLabels, append(labelValues, formatFloat(bound))), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, formatLabels(bucketLabels, append(labelValues, "+Inf")), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, formatLabels(h.labels, labelValues), formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, formatLabels(h.labels, labelValues), count)
+	})
+}
+
+// GaugeFunc 采集时调用函数取值的仪表
+type GaugeFunc struct {
+	metricName string
+	help       string
+	f          func() float64
+}
+
+// NewGaugeFunc 创建并注册仪表，f在每次输出时调用
+func NewGaugeFunc(name, help string, f func() float64) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, f: f}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) name() string {
+	return g.metricName
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.metricName, escapeHelp(g.help), g.metricName, g.metricName, formatFloat(g.f()))
+}
+
+func formatLabels(labels, labelValues []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, label := range labels {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(label)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(labelValues[i]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,50 @@
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	counter := NewCounterVec("test_requests_total", "Test requests.", "route", "status")
+	counter.Inc("/a", "200")
+	counter.Inc("/a", "200")
+	counter.Inc("/b\"", "500")
+	histogram := NewHistogramVec("test_duration_seconds", "Test latency.", []float64{0.1, 1}, "route")
+	histogram.Observe(0.05, "/a")
+	histogram.Observe(0.5, "/a")
+	histogram.Observe(3, "/a")
+	NewGaugeFunc("test_gauge", "Test gauge.", func() float64 { return 1.5 })
+
+	var buf bytes.Buffer
+	WriteTo(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{route="/a",status="200"} 2` + "\n",
+		`test_requests_total{route="/b\"",status="500"} 1` + "\n",
+		"# TYPE test_duration_seconds histogram\n",
+		`test_duration_seconds_bucket{route="/a",le="0.1"} 1` + "\n",
+		`test_duration_seconds_bucket{route="/a",le="1"} 2` + "\n",
+		`test_duration_seconds_bucket{route="/a",le="+Inf"} 3` + "\n",
+		`test_duration_seconds_sum{route="/a"} 3.55` + "\n",
+		`test_duration_seconds_count{route="/a"} 3` + "\n",
+		"# TYPE test_gauge gauge\ntest_gauge 1.5\n",
+		"# TYPE go_goroutines gauge\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestDuplicateMetric(t *testing.T) {
+	NewCounterVec("test_duplicate_total", "Test.")
+	defer func() {
+		if recover() == nil {
+			t.Error("expect panic on duplicate metric")
+		}
+	}()
+	NewCounterVec("test_duplicate_total", "Test.")
+}
@@ -0,0 +1,51 @@
+package metric
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+var processStartTime = time.Now()
+
+var (
+	memStatsLock sync.Mutex
+	memStats     runtime.MemStats
+	memStatsTime time.Time
+)
+
+// readMemStats ReadMemStats会暂停程序，同一次采集的多个指标共用1秒内的结果
+func readMemStats() runtime.MemStats {
+	memStatsLock.Lock()
+	defer memStatsLock.Unlock()
+	if time.Since(memStatsTime) > time.Second {
+		runtime.ReadMemStats(&memStats)
+		memStatsTime = time.Now()
+	}
+	return memStats
+}
+
+// 运行时指标，与rest/monitor.go输出的统计一致
+func init() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from system.", func() float64 {
+		return float64(readMemStats().Sys)
+	})
+	NewGaugeFunc("go_memstats_heap_sys_bytes", "Number of heap bytes obtained from system.", func() float64 {
+		return float64(readMemStats().HeapSys)
+	})
+	NewGaugeFunc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", func() float64 {
+		return float64(readMemStats().HeapAlloc)
+	})
+	NewGaugeFunc("go_memstats_stack_sys_bytes", "Number of bytes obtained from system for stack allocator.", func() float64 {
+		return float64(readMemStats().StackSys)
+	})
+	NewGaugeFunc("go_gc_cycles", "Number of completed GC cycles.", func() float64 {
+		return float64(readMemStats().NumGC)
+	})
+	NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 {
+		return float64(processStartTime.UnixNano()) / 1e9
+	})
+}
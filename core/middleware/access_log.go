@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config/conf"
+)
+
+// 默认记录的最大请求、响应体字节数
+const DEFAULT_BODY_LIMIT = 4096
+
+const redactedValue = "***"
+
+// 默认脱敏字段，不区分大小写
+var DefaultRedactFields = []string{
+	"password", "passwd", "pwd", "secret", "token", "access_token", "refresh_token", "client_secret",
+	"authorization", "cookie", "id_card", "idcard", "id_no", "idno",
+}
+
+// 慢请求日志中脱敏的请求头
+var redactHeaders = map[string]struct{}{"Authorization": {}, "Cookie": {}, "Inner_token_enc": {}}
+
+// AccessLogOptions 访问日志规则，支持运行时更新
+type AccessLogOptions struct {
+	rules atomic.Pointer[accessLogRules]
+}
+
+type sampleRule struct {
+	pattern string
+	ratio   float64
+}
+
+type accessLogRules struct {
+	skip          []string
+	sample        []sampleRule
+	slowThreshold time.Duration
+	bodyPaths     []string
+	bodyLimit     int
+	redactJson    *regexp.Regexp
+	redactForm    *regexp.Regexp
+}
+
+// NewAccessLogOptions skipPaths为额外跳过的路径，如HttpConfig.TraceIgnorePaths
+func NewAccessLogOptions(c conf.AccessLogConfig, skipPaths ...string) *AccessLogOptions {
+	o := &AccessLogOptions{}
+	o.Set(c, skipPaths...)
+	return o
+}
+
+// Set 替换规则
+func (o *AccessLogOptions) Set(c conf.AccessLogConfig, skipPaths ...string) {
+	rules := &accessLogRules{
+		skip:          append(append([]string{}, skipPaths...), c.SkipPaths...),
+		slowThreshold: time.Duration(c.SlowThreshold) * time.Millisecond,
+		bodyPaths:     c.BodyPaths,
+		bodyLimit:     c.BodyLimit,
+	}
+	if rules.bodyLimit <= 0 {
+		rules.bodyLimit = DEFAULT_BODY_LIMIT
+	}
+	for pattern, ratio := range c.Sample {
+		rules.sample = append(rules.sample, sampleRule{pattern: pattern, ratio: ratio})
+	}
+	// 精确路径优先于glob，较长的模式优先
+	sort.Slice(rules.sample, func(i, j int) bool {
+		a, b := rules.sample[i].pattern, rules.sample[j].pattern
+		if isGlob(a) != isGlob(b) {
+			return !isGlob(a)
+		}
+		return len(a) > len(b)
+	})
+	fields := c.RedactFields
+	if len(fields) == 0 {
+		fields = DefaultRedactFields
+	}
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	names := strings.Join(quoted, "|")
+	rules.redactJson = regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	rules.redactForm = regexp.MustCompile(`(?i)((?:^|&)(?:` + names + `)=)[^&]*`)
+	o.rules.Store(rules)
+}
+
+func (o *AccessLogOptions) load() *accessLogRules {
+	if o == nil {
+		return nil
+	}
+	return o.rules.Load()
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchPath 支持path.Match的glob，以/**结尾时匹配该前缀下的所有路径
+func matchPath(pattern, p string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return p == prefix || strings.HasPrefix(p, prefix+"/")
+	}
+	if pattern == p {
+		return true
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *accessLogRules) skipped(p string) bool {
+	return r != nil && matchAny(r.skip, p)
+}
+
+func (r *accessLogRules) captureBody(p string) bool {
+	return r != nil && matchAny(r.bodyPaths, p)
+}
+
+// sampled 未配置采样的路由全部记录
+func (r *accessLogRules) sampled(p string) bool {
+	if r == nil {
+		return true
+	}
+	for _, rule := range r.sample {
+		if matchPath(rule.pattern, p) {
+			return rule.ratio >= 1 || rand.Float64() < rule.ratio
+		}
+	}
+	return true
+}
+
+func (r *accessLogRules) slow(latency time.Duration) bool {
+	return r != nil && r.slowThreshold > 0 && latency >= r.slowThreshold
+}
+
+// redact 脱敏JSON和表单中的敏感字段，截断的内容也能处理
+func (r *accessLogRules) redact(body []byte) string {
+	s := r.redactJson.ReplaceAllString(string(body), `${1}"`+redactedValue+`"`)
+	return r.redactForm.ReplaceAllString(s, "${1}"+redactedValue)
+}
+
+// headers 慢请求附带的请求头，鉴权相关的请求头脱敏
+func headers(c *gin.Context) map[string]string {
+	result := make(map[string]string, len(c.Request.Header))
+	for k, v := range c.Request.Header {
+		if _, ok := redactHeaders[k]; ok {
+			result[k] = redactedValue
+			continue
+		}
+		result[k] = strings.Join(v, ", ")
+	}
+	return result
+}
+
+// captureRequestBody 读取最多limit字节的请求体，并放回以供后续处理读取
+func captureRequestBody(c *gin.Context, limit int) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, false
+	}
+	captured, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+	truncated := len(captured) > limit
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), c.Request.Body), c.Request.Body}
+	if truncated {
+		captured = captured[:limit]
+	}
+	return captured, truncated
+}
+
+// bodyWriter 记录最多limit字节的响应体
+type bodyWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *bodyWriter) Write(p []byte) (int, error) {
+	w.capture(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyWriter) capture(p []byte) {
+	if remain := w.limit - w.body.Len(); remain < len(p) {
+		w.truncated = true
+		p = p[:max(remain, 0)]
+	}
+	w.body.Write(p)
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config/conf"
+)
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	prev := accessLog
+	accessLog = slog.New(slog.NewTextHandler(&buf, nil))
+	defer func() { accessLog = prev }()
+
+	options := NewAccessLogOptions(conf.AccessLogConfig{
+		SkipPaths:     []string{"/static/**"},
+		Sample:        map[string]float64{"/api/ping": 0, "/api/*": 1},
+		SlowThreshold: 20,
+		BodyPaths:     []string{"/api/login"},
+		BodyLimit:     64,
+	}, "/healthz")
+	engine := gin.New()
+	engine.Use(NiceLoggerWithOptions(nil, false, options))
+	engine.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/static/js/app.js", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/api/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/api/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	engine.GET("/api/slow", func(c *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	engine.POST("/api/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		// 记录请求体后，处理函数仍能读取完整请求体
+		c.String(http.StatusOK, `{"token":"abc123","size":`+strconv.Itoa(len(body))+`}`)
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		want    []string
+		notWant []string
+	}{
+		{name: "trace ignore path", method: http.MethodGet, path: "/healthz"},
+		{name: "glob skip", method: http.MethodGet, path: "/static/js/app.js"},
+		{name: "sampled out", method: http.MethodGet, path: "/api/ping"},
+		{name: "error always logged", method: http.MethodGet, path: "/api/fail", want: []string{"level=INFO", "status=500", "path=/api/fail"}},
+		{
+			name:    "slow request",
+			method:  http.MethodGet,
+			path:    "/api/slow",
+			want:    []string{"level=WARN", "slow=true", "headers=", "Authorization:***"},
+			notWant: []string{"secret-token"},
+		},
+		{
+			name:   "body with redaction",
+			method: http.MethodPost,
+			path:   "/api/login",
+			body:   `{"username":"tom","Password":"p@ss","id_card":"110101199001011234","pad":"` + strings.Repeat("x", 40) + `"}`,
+			want: []string{
+				`request_body="{\"username\":\"tom\",\"Password\":\"***\",\"id_card\":\"***\"`,
+				"request_body_truncated=true",
+				`response_body="{\"token\":\"***\",\"size\":116}"`,
+				"response_body_truncated=false",
+			},
+			notWant: []string{"p@ss", "110101199001011234", "abc123"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Bearer secret-token")
+			engine.ServeHTTP(httptest.NewRecorder(), request)
+			out := buf.String()
+			if len(tt.want) == 0 && out != "" {
+				t.Errorf("should not log: %s", out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q: %s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output should not contain %q: %s", notWant, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	rules := NewAccessLogOptions(conf.AccessLogConfig{}).load()
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"password": "a\"b", "age": 1}`, `{"password": "***", "age": 1}`},
+		{`{"token":12345}`, `{"token":"***"}`},
+		{`{"user":{"idCard":"110101"}}`, `{"user":{"idCard":"***"}}`},
+		{`username=tom&password=123&token=abc`, `username=tom&password=***&token=***`},
+		{`{"password":"trunc`, `{"password":"***"`},
+		{`{"name":"password"}`, `{"name":"password"}`},
+	}
+	for _, tt := range tests {
+		if got := rules.redact([]byte(tt.body)); got != tt.want {
+			t.Errorf("redact(%s) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+// AdminAuth 管理接口鉴权。配置了用户名时使用basic auth，
+// 否则使用RPC token：Authorization: Bearer <token>，或与RPC调用相同的inner_token_enc签名。
+// token在每次请求时读取，以支持热更新。
+func AdminAuth(adminConf conf.AdminConfig, token func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminConf.Username != "" {
+			username, password, ok := c.Request.BasicAuth()
+			if !ok || !secureEqual(username, adminConf.Username) || !secureEqual(password, adminConf.Password) {
+				c.Header("WWW-Authenticate", `Basic realm="admin"`)
+				c.JSON(http.StatusUnauthorized, httpx.ErrorWithCode("unauthorized", httpx.STATUS_NO_AUTHENTICATION))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		rpcToken := token()
+		if rpcToken != "" && c.GetHeader("inner_token_enc") != "" {
+			Rpc(conf.RpcConfig{Token: rpcToken})(c)
+			return
+		}
+		bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || rpcToken == "" || !secureEqual(bearer, rpcToken) {
+			c.JSON(http.StatusUnauthorized, httpx.ErrorWithCode("unauthorized", httpx.STATUS_NO_AUTHENTICATION))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
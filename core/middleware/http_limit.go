@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+// MaxBytes 限制请求体大小，Content-Length超出时直接拒绝，未知长度时读取超出部分返回错误。
+// 开启http.httpstatus时返回413，否则为200
+func MaxBytes(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > n {
+			AbortWithError(c, httpx.ErrTooLarge.WithMessage("Request body too large"))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		c.Next()
+	}
+}
+
+// Timeout 为请求设置context超时，处理函数需通过c.Request.Context()感知超时。
+// 超时且尚未写入响应时返回超时错误，开启http.httpstatus时为503，否则为200。
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			AbortWithError(c, httpx.ErrTimeout.WithMessage("Request timeout"))
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+func TestMaxBytes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(MaxBytes(8))
+	engine.POST("/", func(c *gin.Context) {
+		// 未知长度的请求体超出时由rest.Handle返回STATUS_ERROR_TOO_LARGE，参见rest.TestHandleMaxBytes
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, httpx.Ok(string(data)))
+	})
+	defer httpx.SetHttpStatus(false)
+	tests := []struct {
+		name       string
+		body       string
+		httpStatus bool
+		status     int
+		code       int
+	}{
+		{name: "within limit", body: "12345678", status: http.StatusOK, code: httpx.STATUS_SUCCESS},
+		{name: "content length over limit", body: "123456789", status: http.StatusOK, code: httpx.STATUS_ERROR_TOO_LARGE},
+		{name: "content length over limit with http status", body: "123456789", httpStatus: true, status: http.StatusRequestEntityTooLarge, code: httpx.STATUS_ERROR_TOO_LARGE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpx.SetHttpStatus(tt.httpStatus)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			assertResponse(t, w, tt.status, tt.code)
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Timeout(50 * time.Millisecond))
+	engine.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(time.Second):
+			c.JSON(http.StatusOK, httpx.Ok(nil))
+		}
+	})
+	engine.GET("/fast", func(c *gin.Context) {
+		c.JSON(http.StatusOK, httpx.Ok(nil))
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assertResponse(t, w, http.StatusOK, httpx.STATUS_ERROR_TIMEOUT)
+
+	httpx.SetHttpStatus(true)
+	defer httpx.SetHttpStatus(false)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assertResponse(t, w, http.StatusServiceUnavailable, httpx.STATUS_ERROR_TIMEOUT)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assertResponse(t, w, http.StatusOK, httpx.STATUS_SUCCESS)
+}
+
+func assertResponse(t *testing.T, w *httptest.ResponseRecorder, status int, code int) {
+	t.Helper()
+	if w.Code != status {
+		t.Errorf("status = %d, want %d", w.Code, status)
+	}
+	var resp httpx.DefaultResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+	}
+	if resp.Code != code {
+		t.Errorf("code = %d, want %d, message = %s", resp.Code, code, resp.Message)
+	}
+}
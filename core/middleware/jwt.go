@@ -33,13 +33,13 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/logger"
 )
 
 var (
@@ -66,7 +66,7 @@ func JwtAuth(pubKey *rsa.PublicKey) gin.HandlerFunc {
 			tokenString = strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
 		}
 		if tokenString == "" {
-			c.JSON(http.StatusOK, httpx.ErrorWithCode("jwt token required", httpx.STATUS_NO_AUTHENTICATION))
+			AbortWithError(c, httpx.ErrNoAuthentication.WithMessage("jwt token required"))
 			return false
 		}
 		token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -77,16 +77,17 @@ func JwtAuth(pubKey *rsa.PublicKey) gin.HandlerFunc {
 			return publicKey, nil
 		})
 		if err != nil {
-			c.JSON(http.StatusOK, httpx.ErrorWithCode(err.Error(), httpx.STATUS_NO_AUTHENTICATION))
+			AbortWithError(c, httpx.ErrNoAuthentication.WithMessage(err.Error()))
 			return false
 		}
 		claims, ok := token.Claims.(*UserClaims)
 		if !ok || !token.Valid {
-			c.JSON(http.StatusOK, httpx.ErrorWithCode("invalid jwt token", httpx.STATUS_NO_AUTHENTICATION))
+			AbortWithError(c, httpx.ErrNoAuthentication.WithMessage("invalid jwt token"))
 			return false
 		}
 		refreshJwtToken(c, claims)
 		c.Set("jwt/claims", claims)
+		c.Request = c.Request.WithContext(logger.WithSubject(c.Request.Context(), claims.Subject))
 		return true
 	})
 }
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/metric"
+)
+
+var (
+	httpRequests = metric.NewCounterVec("http_requests_total",
+		"Number of HTTP requests by route and status.", "method", "route", "status")
+	httpRequestDuration = metric.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by route and status.", nil, "method", "route", "status")
+	// limiter: local/distributed/shedding
+	limitRejected = metric.NewCounterVec("http_limit_rejected_total",
+		"Number of requests rejected by rate limit or load shedding.", "limiter")
+)
+
+// Metrics 按路由和状态码统计请求数和延迟，未匹配路由的请求归为unmatched，避免路径基数过大
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.Inc(c.Request.Method, route, status)
+		httpRequestDuration.Observe(time.Since(start).Seconds(), c.Request.Method, route, status)
+	}
+}
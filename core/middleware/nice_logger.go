@@ -1,116 +1,123 @@
 package middleware
 
 import (
-	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/logger"
 )
 
 var hostname, _ = os.Hostname()
 
+// 访问日志输出到rest模块，可通过log.modules.rest调整级别
+var accessLog = logger.Module(logger.MODULE_REST)
+
 type LogFormatterParams struct {
 	gin.LogFormatterParams
 	RequestId string
 	Debug     bool
 }
 
-var niceLogFormatter = func(param LogFormatterParams) string {
-	if param.Latency > time.Minute {
-		param.Latency = param.Latency.Truncate(time.Second)
-	}
-
-	var statusColor, methodColor, resetColor string
-	if param.Debug {
-		statusColor = param.StatusCodeColor()
-		methodColor = param.MethodColor()
-		resetColor = param.ResetColor()
-
-		return fmt.Sprintf("[%s|%s|%s] %s %d %s %s %s %s %s %s %s %s\n",
-			hostname,
-			param.RequestId,
-			param.ClientIP,
-			statusColor, param.StatusCode, resetColor,
-			methodColor, param.Method, resetColor,
-			param.Path,
-			param.Request.Proto,
-			param.Latency,
-			param.ErrorMessage,
-		)
-	}
-
-	return fmt.Sprintf("[%s|%s|%s] %d %s %s %s %s %s\n",
-		hostname,
-		param.RequestId,
-		param.ClientIP,
-		param.StatusCode,
-		param.Method,
-		param.Path,
-		param.Request.Proto,
-		param.Latency,
-		param.ErrorMessage,
-	)
-}
-
 // NiceLoggerFormatter 更好的日志中间件
 func NiceLoggerFormatter(formatter func(params LogFormatterParams) string, debug bool) gin.HandlerFunc {
-	conf := gin.LoggerConfig{}
-	if formatter == nil {
-		formatter = niceLogFormatter
-	}
-
-	notlogged := conf.SkipPaths
-
-	var skip map[string]struct{}
-
-	if length := len(notlogged); length > 0 {
-		skip = make(map[string]struct{}, length)
+	return NiceLoggerWithOptions(formatter, debug, nil)
+}
 
-		for _, path := range notlogged {
-			skip[path] = struct{}{}
-		}
-	}
+// NiceLoggerWithOptions 日志中间件，按options跳过、采样路由，慢请求以WARN输出并附带请求头，
+// 指定路由记录脱敏后的请求和响应体。5xx、有错误和慢请求不参与采样，始终记录。
+// formatter为nil时输出结构化属性status、method、path、latency、client_ip等，request_id由context附加
+func NiceLoggerWithOptions(formatter func(params LogFormatterParams) string, debug bool, options *AccessLogOptions) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		rules := options.load()
+		path := c.Request.URL.Path
+		if rules.skipped(path) {
+			c.Next()
+			return
+		}
+
 		// Start timer
 		start := time.Now()
-		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 
+		var requestBody []byte
+		var requestTruncated bool
+		var responseWriter *bodyWriter
+		if rules.captureBody(path) {
+			requestBody, requestTruncated = captureRequestBody(c, rules.bodyLimit)
+			responseWriter = &bodyWriter{ResponseWriter: c.Writer, limit: rules.bodyLimit}
+			c.Writer = responseWriter
+		}
+
 		// Process request
 		c.Next()
 
-		// Log only when path is not being skipped
-		if _, ok := skip[path]; !ok {
-			param := LogFormatterParams{
-				LogFormatterParams: gin.LogFormatterParams{
-					Request: c.Request,
-					Keys:    c.Keys,
-				},
-				Debug: debug,
-			}
+		param := LogFormatterParams{
+			LogFormatterParams: gin.LogFormatterParams{
+				Request: c.Request,
+				Keys:    c.Keys,
+			},
+			Debug: debug,
+		}
 
-			// Stop timer
-			param.TimeStamp = time.Now()
-			param.Latency = param.TimeStamp.Sub(start)
+		// Stop timer
+		param.TimeStamp = time.Now()
+		param.Latency = param.TimeStamp.Sub(start)
 
-			param.ClientIP = c.ClientIP()
-			param.Method = c.Request.Method
-			param.StatusCode = c.Writer.Status()
-			param.ErrorMessage = c.Errors.ByType(gin.ErrorTypePrivate).String()
+		param.ClientIP = c.ClientIP()
+		param.Method = c.Request.Method
+		param.StatusCode = c.Writer.Status()
+		param.ErrorMessage = c.Errors.ByType(gin.ErrorTypePrivate).String()
 
-			param.BodySize = c.Writer.Size()
-			param.RequestId = requestid.Get(c)
+		param.BodySize = c.Writer.Size()
+		param.RequestId = requestid.Get(c)
 
-			if raw != "" {
-				path = path + "?" + raw
-			}
+		if raw != "" {
+			path = path + "?" + raw
+		}
+
+		param.Path = path
 
-			param.Path = path
+		slow := rules.slow(param.Latency)
+		failed := param.StatusCode >= http.StatusInternalServerError || param.ErrorMessage != ""
+		if !slow && !failed && !rules.sampled(c.Request.URL.Path) {
+			return
+		}
 
-			slog.Info(formatter(param))
+		level := slog.LevelInfo
+		var attrs []any
+		if formatter == nil {
+			attrs = append(attrs,
+				"status", param.StatusCode,
+				"method", param.Method,
+				"path", param.Path,
+				"latency", param.Latency,
+				"client_ip", param.ClientIP,
+				"proto", param.Request.Proto,
+				"size", param.BodySize,
+				"host", hostname,
+				"error", param.ErrorMessage,
+			)
+		}
+		if slow {
+			level = slog.LevelWarn
+			attrs = append(attrs, "slow", true, "headers", headers(c))
+		}
+		if responseWriter != nil {
+			attrs = append(attrs,
+				"request_body", rules.redact(requestBody),
+				"request_body_truncated", requestTruncated,
+				"response_body", rules.redact(responseWriter.body.Bytes()),
+				"response_body_truncated", responseWriter.truncated,
+			)
+		}
+		msg := "HTTP"
+		if formatter != nil {
+			msg = formatter(param)
 		}
+		accessLog.Log(c.Request.Context(), level, msg, attrs...)
 	}
 }
@@ -1,49 +1,93 @@
 package middleware
 
 import (
-	"log/slog"
-	"net"
+	"errors"
 	"net/http"
 	"net/http/httputil"
-	"os"
-	"runtime/debug"
 	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config/conf"
 	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/recovery"
 )
 
+// 默认返回给用户的提示
+const DEFAULT_RECOVERY_MESSAGE = "服务器异常，请联系管理员"
+
+// NiceRecovery 恢复panic，返回默认提示
 func NiceRecovery() gin.HandlerFunc {
+	return NiceRecoveryWithConfig(conf.RecoveryConfig{})
+}
+
+// NiceRecoveryWithConfig 恢复panic，记录请求和堆栈并通过recovery.Report上报，
+// 按配置返回提示和HTTP状态码。客户端已断开时只记录日志。
+// panic的值为httpx.BizError时视为业务错误，与rest.Handle返回错误时一致渲染，不上报。
+func NiceRecoveryWithConfig(recoveryConf conf.RecoveryConfig) gin.HandlerFunc {
+	message := recoveryConf.Message
+	if message == "" {
+		message = DEFAULT_RECOVERY_MESSAGE
+	}
+	bizError := httpx.ErrCommon.WithMessage(message)
+	if recoveryConf.HttpStatus {
+		bizError = bizError.WithHttpStatus(http.StatusInternalServerError)
+	}
 	return func(c *gin.Context) {
 		defer func() {
-			if err := recover(); err != nil {
-				// Check for a broken connection, as it is not really a
-				// condition that warrants a panic stack trace.
-				var brokenPipe bool
-				if ne, ok := err.(*net.OpError); ok {
-					if se, ok := ne.Err.(*os.SyscallError); ok {
-						if strings.Contains(strings.ToLower(se.Error()), "broken pipe") || strings.Contains(strings.ToLower(se.Error()), "connection reset by peer") {
-							brokenPipe = true
-						}
+			if v := recover(); v != nil {
+				ctx := c.Request.Context()
+				// 连接已断开，无法再写入响应，也不需要堆栈
+				if isBrokenPipe(v) {
+					accessLog.WarnContext(ctx, "Connection broken.", "path", c.Request.URL.Path, "error", v)
+					c.Abort()
+					return
+				}
+				if err, ok := v.(error); ok && errors.As(err, new(*httpx.BizError)) {
+					if c.Writer.Written() {
+						c.Abort()
+						return
 					}
+					AbortWithError(c, err)
+					return
 				}
-				slog.Error("Nice recovery.", "error", err)
-				if brokenPipe {
-					// If the connection is dead, we can't write a status to it.
-					c.JSON(http.StatusOK, httpx.Error(err.(error).Error()))
-					c.Abort()
-				} else {
-					httpRequest, _ := httputil.DumpRequest(c.Request, false)
-					headers := strings.Split(string(httpRequest), "\r\n")
-					headersToStr := strings.Join(headers, "\r\n")
-					slog.Error("[Recovery] Panic recovered.")
-					slog.Error(headersToStr)
-					slog.Error(string(debug.Stack()))
-					c.JSON(http.StatusOK, httpx.Error("服务器异常，请联系管理员"))
+				p := recovery.NewPanic(ctx, recovery.SOURCE_HTTP, v)
+				p.Method = c.Request.Method
+				p.Path = c.Request.URL.Path
+				p.Request = dumpRequest(c.Request)
+				recovery.Report(ctx, p)
+				if c.Writer.Written() {
 					c.Abort()
+					return
 				}
+				AbortWithError(c, bizError)
 			}
 		}()
 		c.Next()
 	}
 }
+
+// isBrokenPipe 客户端断开连接，或handler主动以http.ErrAbortHandler中止
+func isBrokenPipe(v any) bool {
+	err, ok := v.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(err, http.ErrAbortHandler) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
+
+// dumpRequest 请求行和请求头，鉴权相关的请求头脱敏
+func dumpRequest(r *http.Request) string {
+	clone := r.Clone(r.Context())
+	for k := range clone.Header {
+		if _, ok := redactHeaders[k]; ok {
+			clone.Header.Set(k, redactedValue)
+		}
+	}
+	dump, _ := httputil.DumpRequest(clone, false)
+	return string(dump)
+}
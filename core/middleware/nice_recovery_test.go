@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/recovery"
+)
+
+func TestNiceRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reported := make(chan *recovery.Panic, 1)
+	recovery.SetReporters(recovery.PanicReporterFunc(func(ctx context.Context, p *recovery.Panic) error {
+		reported <- p
+		return nil
+	}))
+	defer recovery.SetReporters()
+
+	tests := []struct {
+		name         string
+		config       conf.RecoveryConfig
+		panicValue   any
+		wantStatus   int
+		wantBody     string
+		wantReported bool
+	}{
+		{"default", conf.RecoveryConfig{}, "boom", http.StatusOK, DEFAULT_RECOVERY_MESSAGE, true},
+		{"http status", conf.RecoveryConfig{HttpStatus: true, Message: "internal error"}, "boom", http.StatusInternalServerError, "internal error", true},
+		{"broken pipe", conf.RecoveryConfig{}, syscall.EPIPE, http.StatusOK, "", false},
+		{"abort handler", conf.RecoveryConfig{}, http.ErrAbortHandler, http.StatusOK, "", false},
+		{"biz error", conf.RecoveryConfig{}, httpx.ErrParam.WithMessage("bad id"), http.StatusOK, `{"code":-896,"message":"bad id","data":null}`, false},
+		{"biz error status", conf.RecoveryConfig{}, httpx.ErrParam.WithHttpStatus(http.StatusBadRequest), http.StatusBadRequest, `"message":"invalid parameters"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(NiceRecoveryWithConfig(tt.config))
+			engine.GET("/panic", func(c *gin.Context) { panic(tt.panicValue) })
+			request := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			request.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, request)
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("response = %d %s, want %d %q", w.Code, w.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+			if tt.wantBody == "" && w.Body.Len() > 0 {
+				t.Errorf("broken connection should not be written: %s", w.Body.String())
+			}
+			select {
+			case p := <-reported:
+				if !tt.wantReported {
+					t.Fatalf("unexpected report: %v", p.Value)
+				}
+				if p.Source != recovery.SOURCE_HTTP || p.Path != "/panic" || p.Stack == "" {
+					t.Errorf("panic = %+v", p)
+				}
+				if strings.Contains(p.Request, "secret") || !strings.Contains(p.Request, "GET /panic") {
+					t.Errorf("request dump = %q", p.Request)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantReported {
+					t.Fatal("panic not reported")
+				}
+			}
+		})
+	}
+}
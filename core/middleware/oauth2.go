@@ -29,6 +29,7 @@ import (
 	"github.com/go-oauth2/oauth2/server"
 	"github.com/kappere/go-rest/core/config/conf"
 	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/logger"
 	"gopkg.in/oauth2.v3"
 	"gopkg.in/oauth2.v3/generates"
 	"gopkg.in/oauth2.v3/manage"
@@ -173,13 +174,13 @@ func OAuth2Client(oauth2Conf *conf.OAuth2Config, engine *gin.Engine, db *gorm.DB
 	return func(c *gin.Context) {
 		tokenInfo, err := srv.ValidationBearerToken(c.Request)
 		if err != nil {
-			c.JSON(http.StatusOK, httpx.ErrorWithCode(err.Error(), httpx.STATUS_NO_AUTHORIZATION))
-			c.Abort()
+			AbortWithError(c, httpx.ErrNoAuthorization.WithMessage(err.Error()))
 			return
 		}
 		// add oauth info to context
 		c.Set("oauth/client_id", tokenInfo.GetClientID())
 		c.Set("oauth/user_id", tokenInfo.GetUserID())
+		c.Request = c.Request.WithContext(logger.WithClientID(c.Request.Context(), tokenInfo.GetClientID()))
 		c.Next()
 	}
 }
@@ -5,9 +5,9 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,36 +19,63 @@ import (
 
 // PeriodLimit 分布式限流中间件
 func PeriodLimitDistributedMiddleware(periodLimitConfig conf.PeriodLimitConfig, redisConfig conf.RedisConfig, opts ...PeriodOption) (gin.HandlerFunc, func()) {
+	limit := NewPeriodLimitDistributed(periodLimitConfig, redisConfig, opts...)
+	return limit.Handler(), limit.close
+}
+
+// redis客户端构造函数，测试时替换
+var (
+	newRedisClient        = rest_redis.NewRedisClient
+	newRedisClusterClient = rest_redis.NewRedisClusterClient
+)
+
+// NewPeriodLimitDistributed 创建分布式限流器，可通过Update热更新窗口和配额
+func NewPeriodLimitDistributed(periodLimitConfig conf.PeriodLimitConfig, redisConfig conf.RedisConfig, opts ...PeriodOption) *PeriodLimit {
 	var limitStore *redis.Client
 	var clusterLimitStore *redis.ClusterClient
 	addrLen := len(strings.Split(redisConfig.Addr, ","))
 	var err error
+	// 多个地址为集群
 	if addrLen > 1 {
-		limitStore, err = rest_redis.NewRedisClient(redisConfig)
+		clusterLimitStore, err = newRedisClusterClient(redisConfig)
 	} else if addrLen == 1 {
-		clusterLimitStore, err = rest_redis.NewRedisClusterClient(redisConfig)
+		limitStore, err = newRedisClient(redisConfig)
 	}
 	if err != nil {
 		panic(err)
 	}
-	limit := newPeriodLimit(periodLimitConfig.Period, periodLimitConfig.Quota, limitStore, clusterLimitStore, "REST_PERIOD_LIMIT", opts...)
+	return newPeriodLimit(periodLimitConfig.Period, periodLimitConfig.Quota, limitStore, clusterLimitStore, "REST_PERIOD_LIMIT", opts...)
+}
+
+// Handler 限流中间件
+func (h *PeriodLimit) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-			r, err := limit.take(c.Request.RequestURI)
-			if err != nil {
-				slog.Error("Peroid limit middleware has error,", "URI", c.Request.RequestURI)
-				c.JSON(http.StatusOK, httpx.ErrorWithCode("Peroid limit middleware has error", httpx.STATUS_ERROR_LIMIT))
-				c.Abort()
-				return
-			}
-			if r == OverQuota {
-				c.JSON(http.StatusOK, httpx.ErrorWithCode("Resource limited", httpx.STATUS_ERROR_LIMIT))
-				c.Abort()
-				return
-			}
-			c.Next()
-		}, func() {
-			limit.close()
+		r, err := h.take(c.Request.RequestURI)
+		if err != nil {
+			slog.Error("Peroid limit middleware has error,", "URI", c.Request.RequestURI)
+			AbortWithError(c, httpx.ErrLimit.WithMessage("Peroid limit middleware has error").WithCause(err))
+			return
 		}
+		if r == OverQuota {
+			limitRejected.Inc("distributed")
+			AbortWithError(c, httpx.ErrLimit.WithMessage("Resource limited"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Update 更新窗口和配额，对下一次请求生效
+func (h *PeriodLimit) Update(periodLimitConfig conf.PeriodLimitConfig) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.period = periodLimitConfig.Period
+	h.quota = periodLimitConfig.Quota
+}
+
+// Close 关闭redis连接
+func (h *PeriodLimit) Close() {
+	h.close()
 }
 
 // to be compatible with aliyun redis, we cannot use `local key = KEYS[1]` to reuse the key
@@ -92,6 +119,7 @@ type (
 		clusterLimitStore *redis.ClusterClient
 		keyPrefix         string
 		align             bool
+		lock              sync.RWMutex
 	}
 )
 
@@ -124,16 +152,19 @@ func (h *PeriodLimit) close() {
 
 // Take requests a permit, it returns the permit state.
 func (h *PeriodLimit) take(key string) (int, error) {
+	h.lock.RLock()
+	quota, expireSeconds := h.quota, h.calcExpireSeconds()
+	h.lock.RUnlock()
 	var resp *redis.Cmd
 	if h.clusterLimitStore != nil {
 		resp = h.clusterLimitStore.Eval(context.Background(), PERIOD_SCRIPT, []string{h.keyPrefix + key},
-			strconv.Itoa(h.quota),
-			strconv.Itoa(h.calcExpireSeconds()),
+			strconv.Itoa(quota),
+			strconv.Itoa(expireSeconds),
 		)
 	} else {
 		resp = h.limitStore.Eval(context.Background(), PERIOD_SCRIPT, []string{h.keyPrefix + key},
-			strconv.Itoa(h.quota),
-			strconv.Itoa(h.calcExpireSeconds()),
+			strconv.Itoa(quota),
+			strconv.Itoa(expireSeconds),
 		)
 	}
 
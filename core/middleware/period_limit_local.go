@@ -3,7 +3,6 @@ package middleware
 
 import (
 	"log/slog"
-	"net/http"
 	"sync"
 	"time"
 
@@ -14,45 +13,61 @@ import (
 
 // PeriodLimit 本地限流中间件
 func PeriodLimitLocalMiddleware(periodLimitConfig conf.PeriodLimitConfig) gin.HandlerFunc {
-	limit := newPeriodLimitLocal(periodLimitConfig.Period, periodLimitConfig.Quota)
+	return NewPeriodLimitLocal(periodLimitConfig).Handler()
+}
+
+// NewPeriodLimitLocal 创建本地限流器，可通过Update热更新窗口和配额
+func NewPeriodLimitLocal(periodLimitConfig conf.PeriodLimitConfig) *PeriodLimitLocal {
+	return newPeriodLimitLocal(periodLimitConfig.Period, periodLimitConfig.Quota)
+}
+
+// Handler 限流中间件
+func (h *PeriodLimitLocal) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		r, err := limit.take(c.Request.RequestURI)
+		r, err := h.take(c.Request.RequestURI)
 		if err != nil {
 			slog.Error("Peroid limit middleware has error,", "URI", c.Request.RequestURI)
-			c.JSON(http.StatusOK, httpx.ErrorWithCode("Peroid limit middleware has error", httpx.STATUS_ERROR_LIMIT))
-			c.Abort()
+			AbortWithError(c, httpx.ErrLimit.WithMessage("Peroid limit middleware has error").WithCause(err))
 			return
 		}
 		if r == OverQuota {
-			c.JSON(http.StatusOK, httpx.ErrorWithCode("Resource limited", httpx.STATUS_ERROR_LIMIT))
-			c.Abort()
+			limitRejected.Inc("local")
+			AbortWithError(c, httpx.ErrLimit.WithMessage("Resource limited"))
 			return
 		}
 		c.Next()
 	}
 }
 
+// Update 更新窗口和配额，已有窗口在过期后按新窗口计算
+func (h *PeriodLimitLocal) Update(periodLimitConfig conf.PeriodLimitConfig) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.period = periodLimitConfig.Period
+	h.quota = periodLimitConfig.Quota
+}
+
 type (
 	PeriodLimitLocal struct {
-		period int
-		quota  int
-		lock   sync.Mutex
+		period      int
+		quota       int
+		lock        sync.Mutex
+		reqTimesMap map[string]ReqTimes
 	}
 )
 
 // newPeriodLimit returns a PeriodLimit with given parameters.
 func newPeriodLimitLocal(period, quota int) *PeriodLimitLocal {
 	limiter := &PeriodLimitLocal{
-		period: period,
-		quota:  quota,
-		lock:   sync.Mutex{},
+		period:      period,
+		quota:       quota,
+		lock:        sync.Mutex{},
+		reqTimesMap: make(map[string]ReqTimes),
 	}
 
 	return limiter
 }
 
-var reqTimesMap map[string]ReqTimes
-
 type ReqTimes struct {
 	times      int
 	expireTime time.Time
@@ -61,20 +76,19 @@ type ReqTimes struct {
 func (h *PeriodLimitLocal) take(key string) (int, error) {
 	h.lock.Lock()
 	defer h.lock.Unlock()
-	reqTimes, exists := reqTimesMap[key]
-	if time.Now().After(reqTimes.expireTime) {
+	reqTimes, exists := h.reqTimesMap[key]
+	if exists && time.Now().After(reqTimes.expireTime) {
 		exists = false
-		delete(reqTimesMap, key)
 	}
-	reqTimes.times++
-	code := Unknown
 	if !exists {
-		code = Allowed
-		reqTimesMap[key] = ReqTimes{
-			times:      1,
+		reqTimes = ReqTimes{
 			expireTime: time.Now().Add(time.Duration(h.period) * time.Second),
 		}
-	} else if reqTimes.times < h.quota {
+	}
+	reqTimes.times++
+	h.reqTimesMap[key] = reqTimes
+	code := Unknown
+	if reqTimes.times < h.quota {
 		code = Allowed
 	} else if reqTimes.times == h.quota {
 		code = HitQuota
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/kappere/go-rest/core/config/conf"
+)
+
+func TestPeriodLimitDistributedClient(t *testing.T) {
+	single, cluster := newRedisClient, newRedisClusterClient
+	defer func() {
+		newRedisClient, newRedisClusterClient = single, cluster
+	}()
+	newRedisClient = func(c conf.RedisConfig) (*redis.Client, error) {
+		return redis.NewClient(&redis.Options{Addr: c.Addr}), nil
+	}
+	newRedisClusterClient = func(c conf.RedisConfig) (*redis.ClusterClient, error) {
+		return redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{c.Addr}}), nil
+	}
+
+	tests := []struct {
+		addr    string
+		cluster bool
+	}{
+		{"127.0.0.1:6379", false},
+		{"127.0.0.1:7000,127.0.0.1:7001,127.0.0.1:7002", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			limit := NewPeriodLimitDistributed(conf.PeriodLimitConfig{Period: 1, Quota: 1}, conf.RedisConfig{Addr: tt.addr})
+			defer limit.Close()
+			if got := limit.clusterLimitStore != nil; got != tt.cluster || (limit.limitStore != nil) == tt.cluster {
+				t.Errorf("cluster client = %v, want %v", got, tt.cluster)
+			}
+		})
+	}
+}
+
+func TestPeriodLimitLocal(t *testing.T) {
+	// 未经其他初始化直接使用
+	limit := NewPeriodLimitLocal(conf.PeriodLimitConfig{Period: 60, Quota: 2})
+	want := []int{Allowed, HitQuota, OverQuota}
+	for i, code := range want {
+		got, err := limit.take("/api")
+		if err != nil || got != code {
+			t.Errorf("take #%d = %d, %v, want %d", i+1, got, err, code)
+		}
+	}
+	if got, _ := limit.take("/other"); got != Allowed {
+		t.Errorf("take /other = %d, want %d", got, Allowed)
+	}
+}
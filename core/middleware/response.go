@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+// ResponseFormat 设置路由组的响应格式，覆盖httpx.SetResponse设置的全局格式。
+// 对RenderOk、AbortWithError以及使用它们的rest.Handle、鉴权中间件和NiceRecovery生效，需放在鉴权中间件之前。
+//
+//	open := engine.Group("/open", middleware.ResponseFormat(&httpx.ProblemResponse{}), middleware.JwtAuth(nil))
+func ResponseFormat(format httpx.Response) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(httpx.WithResponse(c.Request.Context(), format))
+		c.Next()
+	}
+}
+
+// RenderOk 以当前请求的响应格式返回成功结果
+func RenderOk(c *gin.Context, data any) {
+	render(c, http.StatusOK, httpx.ResponseFromContext(c.Request.Context()).Create(data, httpx.STATUS_SUCCESS, ""))
+}
+
+// AbortWithError 将错误转换为httpx.BizError，以当前请求的响应格式渲染并中止。
+// HTTP状态码参见httpx.HttpStatus，响应格式实现了httpx.StatusResponse时始终使用BizError的HTTP状态码。
+func AbortWithError(c *gin.Context, err error) {
+	bizError := httpx.AsBizError(err)
+	response := httpx.ResponseFromContext(c.Request.Context()).Create(bizError.Details, bizError.Code, bizError.Message)
+	status := httpx.HttpStatus(c.Request.Context(), bizError)
+	if statusResponse, ok := response.(httpx.StatusResponse); ok {
+		status = bizError.Status()
+		statusResponse.SetStatus(status)
+	}
+	c.Abort()
+	render(c, status, response)
+}
+
+// render 不转义HTML字符，与PureJSON一致
+func render(c *gin.Context, status int, response httpx.Response) {
+	contentType := httpx.MIME_JSON + "; charset=utf-8"
+	if statusResponse, ok := response.(httpx.StatusResponse); ok {
+		contentType = statusResponse.ContentType()
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(response); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(status, contentType, buf.Bytes())
+}
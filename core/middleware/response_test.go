@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+func TestResponseFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(NiceRecovery())
+	for _, group := range []*gin.RouterGroup{
+		engine.Group("/default"),
+		engine.Group("/problem", ResponseFormat(&httpx.ProblemResponse{TypeBase: "https://errors.example.com/"})),
+	} {
+		group.GET("/ok", func(c *gin.Context) { RenderOk(c, map[string]any{"id": 7, "name": "<tom>"}) })
+		group.GET("/param", func(c *gin.Context) {
+			AbortWithError(c, httpx.ErrParam.WithDetails([]string{"id is required"}))
+		})
+		group.GET("/error", func(c *gin.Context) { AbortWithError(c, errors.New("boom")) })
+		group.GET("/panic", func(c *gin.Context) { panic(httpx.ErrNoAuthentication) })
+	}
+
+	tests := []struct {
+		path            string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		// DecodeResponse解析的结果
+		wantData string
+		wantCode int
+	}{
+		{"/default/ok", http.StatusOK, "application/json; charset=utf-8",
+			`{"code":0,"message":"","data":{"id":7,"name":"<tom>"}}`, "map[id:7 name:<tom>]", 0},
+		{"/default/param", http.StatusOK, "application/json; charset=utf-8",
+			`{"code":-896,"message":"invalid parameters","data":["id is required"]}`, "", httpx.STATUS_ERROR_PARAM},
+		{"/default/panic", http.StatusOK, "application/json; charset=utf-8",
+			`{"code":-999,"message":"authentication required","data":null}`, "", httpx.STATUS_NO_AUTHENTICATION},
+		{"/problem/ok", http.StatusOK, "application/json; charset=utf-8",
+			`{"id":7,"name":"<tom>"}`, "map[id:7 name:<tom>]", 0},
+		{"/problem/param", http.StatusBadRequest, "application/problem+json",
+			`{"type":"https://errors.example.com/-896","title":"invalid parameters","status":400,"detail":"invalid parameters","code":-896,"details":["id is required"]}`,
+			"", httpx.STATUS_ERROR_PARAM},
+		{"/problem/error", http.StatusInternalServerError, "application/problem+json",
+			`{"type":"https://errors.example.com/-1","title":"error","status":500,"detail":"boom","code":-1}`, "", httpx.STATUS_ERROR_COMMON},
+		{"/problem/panic", http.StatusUnauthorized, "application/problem+json",
+			`{"type":"https://errors.example.com/-999","title":"authentication required","status":401,"detail":"authentication required","code":-999}`,
+			"", httpx.STATUS_NO_AUTHENTICATION},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			body := w.Body.String()
+			if w.Code != tt.wantStatus || w.Header().Get("Content-Type") != tt.wantContentType || body != tt.wantBody+"\n" {
+				t.Errorf("response = %d %s %s, want %d %s %s", w.Code, w.Header().Get("Content-Type"), body, tt.wantStatus, tt.wantContentType, tt.wantBody)
+			}
+			var format httpx.Response = &httpx.DefaultResponse{}
+			if strings.HasPrefix(tt.path, "/problem/") {
+				format = &httpx.ProblemResponse{}
+			}
+			data, err := httpx.DecodeResponse(format, w.Code, w.Header().Get("Content-Type"), w.Body.Bytes())
+			if tt.wantCode == 0 {
+				if err != nil || tt.wantData != fmt.Sprint(data) {
+					t.Errorf("DecodeResponse = %v %v, want %s", data, err, tt.wantData)
+				}
+				return
+			}
+			var bizError *httpx.BizError
+			if !errors.As(err, &bizError) || bizError.Code != tt.wantCode {
+				t.Errorf("DecodeResponse error = %#v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -25,25 +25,21 @@ func Rpc(rpcConf conf.RpcConfig) gin.HandlerFunc {
 		if rpcConf.Token != "" {
 			rpc_token := c.GetHeader("inner_token_enc")
 			if rpc_token == "" {
-				c.JSON(http.StatusForbidden, httpx.ErrorWithCode("missing rpc token", -552))
-				c.Abort()
+				AbortWithError(c, httpx.ErrRpcToken.WithMessage("missing rpc token").WithHttpStatus(http.StatusForbidden))
 				return
 			}
 			tks := strings.Split(rpc_token, "#")
 			if len(tks) != 3 {
-				c.JSON(http.StatusForbidden, httpx.ErrorWithCode("invalid rpc format", -552))
-				c.Abort()
+				AbortWithError(c, httpx.ErrRpcToken.WithMessage("invalid rpc format").WithHttpStatus(http.StatusForbidden))
 				return
 			}
 			timestamp, err := strconv.ParseInt(tks[2], 10, 64)
 			if err != nil {
-				c.JSON(http.StatusForbidden, httpx.ErrorWithCode("invalid timestamp!", -550))
-				c.Abort()
+				AbortWithError(c, httpx.ErrRpcTimestamp.WithMessage("invalid timestamp!").WithHttpStatus(http.StatusForbidden))
 				return
 			}
 			if abs(timestamp-time.Now().UnixMilli()) > 1000*180 {
-				c.JSON(http.StatusForbidden, httpx.ErrorWithCode("sync time please!", -550))
-				c.Abort()
+				AbortWithError(c, httpx.ErrRpcTimestamp.WithMessage("sync time please!").WithHttpStatus(http.StatusForbidden))
 				return
 			}
 			enc := tks[0]
@@ -51,8 +47,7 @@ func Rpc(rpcConf conf.RpcConfig) gin.HandlerFunc {
 			hash.Write([]byte(rpcConf.Token + "#" + tks[1] + "#" + tks[2]))
 			enc2 := hex.EncodeToString(hash.Sum(nil))
 			if enc == "" || enc != enc2 {
-				c.JSON(http.StatusForbidden, httpx.ErrorWithCode("invalid rpc token!", -552))
-				c.Abort()
+				AbortWithError(c, httpx.ErrRpcToken.WithMessage("invalid rpc token!").WithHttpStatus(http.StatusForbidden))
 				return
 			}
 		}
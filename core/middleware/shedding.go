@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/tool/load"
+)
+
+// Shedding 自适应降载中间件，CPU超过阈值且并发过高时拒绝请求，返回httpx.ErrServiceUnavailable，参见load.AdaptiveShedder。
+// 丢弃数记录在http_limit_rejected_total{limiter="shedding"}，过载时大量丢弃，日志只在debug级别输出。
+func Shedding(shedder load.Shedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		promise, err := shedder.Allow()
+		if err != nil {
+			limitRejected.Inc("shedding")
+			if ctx := c.Request.Context(); accessLog.Enabled(ctx, slog.LevelDebug) {
+				accessLog.DebugContext(ctx, "Dropped request by shedding,", "URI", c.Request.RequestURI, "cpu", load.CpuUsage())
+			}
+			AbortWithError(c, httpx.ErrServiceUnavailable.WithMessage("Service overloaded"))
+			return
+		}
+		defer func() {
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				promise.Fail()
+			} else {
+				promise.Pass()
+			}
+		}()
+		c.Next()
+	}
+}
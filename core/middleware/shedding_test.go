@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/tool/load"
+)
+
+type testShedder struct {
+	drop   bool
+	passed int
+	failed int
+}
+
+func (s *testShedder) Allow() (load.Promise, error) {
+	if s.drop {
+		return nil, load.ErrServiceOverloaded
+	}
+	return s, nil
+}
+
+func (s *testShedder) Pass() { s.passed++ }
+func (s *testShedder) Fail() { s.failed++ }
+
+func TestShedding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer httpx.SetHttpStatus(false)
+	shedder := &testShedder{}
+	engine := gin.New()
+	engine.Use(Shedding(shedder))
+	engine.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, httpx.Ok(nil))
+	})
+	tests := []struct {
+		name       string
+		drop       bool
+		httpStatus bool
+		status     int
+		code       int
+	}{
+		{name: "pass", status: http.StatusOK, code: httpx.STATUS_SUCCESS},
+		{name: "drop", drop: true, status: http.StatusOK, code: httpx.STATUS_ERROR_UNAVAILABLE},
+		{name: "drop with http status", drop: true, httpStatus: true, status: http.StatusServiceUnavailable, code: httpx.STATUS_ERROR_UNAVAILABLE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shedder.drop = tt.drop
+			httpx.SetHttpStatus(tt.httpStatus)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assertResponse(t, w, tt.status, tt.code)
+		})
+	}
+	if shedder.passed != 1 || shedder.failed != 0 {
+		t.Errorf("passed = %d, failed = %d, want 1, 0", shedder.passed, shedder.failed)
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/trace"
+)
+
+// TRACE_ID_HEADER 响应头中返回trace id，便于排查问题
+const TRACE_ID_HEADER = "X-Trace-Id"
+
+// Trace 解析请求头traceparent（没有时新建trace），为每个请求创建服务端span，
+// 并通过c.Request.Context()传递给下游RPC、数据库和redis调用
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := trace.StartRemote(c.Request.Context(), c.GetHeader(trace.TRACEPARENT_HEADER), c.Request.Method+" "+route, trace.KIND_SERVER)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(TRACE_ID_HEADER, span.SpanContext.TraceID.String())
+		span.SetAttr("http.method", c.Request.Method)
+		span.SetAttr("http.route", route)
+		span.SetAttr("http.target", c.Request.URL.RequestURI())
+		span.SetAttr("http.client_ip", c.ClientIP())
+		defer span.End()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttr("http.status_code", status)
+		if err := c.Errors.Last(); err != nil {
+			span.SetError(err.Err)
+		} else if status >= http.StatusInternalServerError {
+			span.SetError(errors.New(http.StatusText(status)))
+		}
+	}
+}
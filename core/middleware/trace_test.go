@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/trace"
+)
+
+func TestTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Trace())
+	var span *trace.Span
+	engine.GET("/user/:id", func(c *gin.Context) {
+		span = trace.FromContext(c.Request.Context())
+		c.Status(http.StatusInternalServerError)
+	})
+
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+	}{
+		{"from upstream", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"new trace", "", ""},
+		{"invalid upstream", "00-xyz-00f067aa0ba902b7-01", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span = nil
+			request := httptest.NewRequest(http.MethodGet, "/user/1", nil)
+			if tt.traceparent != "" {
+				request.Header.Set(trace.TRACEPARENT_HEADER, tt.traceparent)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, request)
+			if span == nil {
+				t.Fatal("span not in request context")
+			}
+			traceID := span.SpanContext.TraceID.String()
+			if tt.wantTraceID != "" && traceID != tt.wantTraceID {
+				t.Errorf("trace id = %s, want %s", traceID, tt.wantTraceID)
+			}
+			if w.Header().Get(TRACE_ID_HEADER) != traceID {
+				t.Errorf("%s = %s, want %s", TRACE_ID_HEADER, w.Header().Get(TRACE_ID_HEADER), traceID)
+			}
+			if span.Name != "GET /user/:id" || span.Kind != trace.KIND_SERVER {
+				t.Errorf("span = %s kind %d", span.Name, span.Kind)
+			}
+			if span.Attributes["http.status_code"] != http.StatusInternalServerError || span.StatusError == "" {
+				t.Errorf("span status = %v %q", span.Attributes["http.status_code"], span.StatusError)
+			}
+		})
+	}
+}
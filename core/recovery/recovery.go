@@ -0,0 +1,155 @@
+// panic上报，HTTP请求、SafeGo启动的goroutine和定时任务中的panic统一交给PanicReporter
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/kappere/go-rest/core/logger"
+	"github.com/kappere/go-rest/core/metric"
+	"github.com/kappere/go-rest/core/trace"
+)
+
+// panic来源
+const (
+	SOURCE_HTTP      = "http"
+	SOURCE_GOROUTINE = "goroutine"
+	SOURCE_TASK      = "task"
+)
+
+var panics = metric.NewCounterVec("panics_total", "Number of recovered panics by source.", "source")
+
+// Panic 一次panic的上下文
+type Panic struct {
+	Source string    `json:"source"`
+	Value  string    `json:"value"`
+	Stack  string    `json:"stack"`
+	Time   time.Time `json:"time"`
+	// Request 请求dump，鉴权相关请求头已脱敏，非HTTP来源为空
+	Request string `json:"request,omitempty"`
+	Method  string `json:"method,omitempty"`
+	// Path 请求路径，定时任务为任务名
+	Path      string `json:"path,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	TraceID   string `json:"traceId,omitempty"`
+}
+
+// NewPanic 在recover处调用，记录当前goroutine的堆栈
+func NewPanic(ctx context.Context, source string, value any) *Panic {
+	return &Panic{
+		Source:    source,
+		Value:     fmt.Sprint(value),
+		Stack:     string(debug.Stack()),
+		Time:      time.Now(),
+		RequestID: logger.RequestIDFromContext(ctx),
+		TraceID:   trace.TraceIDFromContext(ctx),
+	}
+}
+
+// PanicReporter 接收panic，如发送到webhook、告警平台。Report在独立goroutine中调用，不阻塞请求
+type PanicReporter interface {
+	Report(ctx context.Context, p *Panic) error
+}
+
+// PanicReporterFunc 函数形式的PanicReporter
+type PanicReporterFunc func(ctx context.Context, p *Panic) error
+
+func (f PanicReporterFunc) Report(ctx context.Context, p *Panic) error {
+	return f(ctx, p)
+}
+
+// 单次上报超时
+const REPORT_TIMEOUT = 5 * time.Second
+
+var (
+	reportersLock sync.RWMutex
+	reporters     []PanicReporter
+	// 正在进行的上报，Flush时等待
+	reporting sync.WaitGroup
+)
+
+// AddReporter 注册上报器
+func AddReporter(r ...PanicReporter) {
+	reportersLock.Lock()
+	defer reportersLock.Unlock()
+	reporters = append(reporters, r...)
+}
+
+// SetReporters 替换所有上报器，传空时清除
+func SetReporters(r ...PanicReporter) {
+	reportersLock.Lock()
+	defer reportersLock.Unlock()
+	reporters = append([]PanicReporter{}, r...)
+}
+
+type reportersKey struct{}
+
+// WithReporters 为ctx附加上报器，Report时与全局上报器一起通知。
+// rest.Server以此为每个请求附加自身配置的webhook，SafeGo传入请求ctx时同样生效
+func WithReporters(ctx context.Context, r ...PanicReporter) context.Context {
+	rs, _ := ctx.Value(reportersKey{}).([]PanicReporter)
+	return context.WithValue(ctx, reportersKey{}, append(rs[:len(rs):len(rs)], r...))
+}
+
+// Report 记录错误日志并异步通知全局和ctx中的上报器，上报器自身panic或失败只记录日志
+func Report(ctx context.Context, p *Panic) {
+	panics.Inc(p.Source)
+	slog.ErrorContext(ctx, "Panic recovered.", "source", p.Source, "error", p.Value, "request", p.Request, "stack", p.Stack)
+	reportersLock.RLock()
+	rs := reporters
+	reportersLock.RUnlock()
+	if local, ok := ctx.Value(reportersKey{}).([]PanicReporter); ok {
+		rs = append(rs[:len(rs):len(rs)], local...)
+	}
+	for _, r := range rs {
+		reporting.Add(1)
+		go func(r PanicReporter) {
+			defer reporting.Done()
+			defer func() {
+				if v := recover(); v != nil {
+					slog.Error("Panic reporter panicked.", "error", v)
+				}
+			}()
+			// 请求结束后ctx会被取消，上报使用独立的超时
+			reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), REPORT_TIMEOUT)
+			defer cancel()
+			if err := r.Report(reportCtx, p); err != nil {
+				slog.Error("Report panic failed.", "error", err)
+			}
+		}(r)
+	}
+}
+
+// Flush 等待进行中的上报完成，ctx结束时返回ctx.Err()
+func Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		reporting.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SafeGo 启动goroutine，panic时记录日志并上报，不会导致进程退出。
+// ctx用于关联请求ID和trace，不会传给f，f需要时自行捕获。
+//
+//	recovery.SafeGo(c.Request.Context(), func() { sendMail(user) })
+func SafeGo(ctx context.Context, f func()) {
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				Report(ctx, NewPanic(ctx, SOURCE_GOROUTINE, v))
+			}
+		}()
+		f()
+	}()
+}
@@ -0,0 +1,91 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSafeGoWebhook(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		payload["auth"] = r.Header.Get("X-Token")
+		received <- payload
+	}))
+	defer srv.Close()
+	SetReporters(NewWebhookReporter(srv.URL, "test", "X-Token=abc"))
+	defer SetReporters()
+
+	SafeGo(context.Background(), func() {
+		panic("boom")
+	})
+	select {
+	case payload := <-received:
+		if payload["source"] != SOURCE_GOROUTINE || payload["value"] != "boom" || payload["auth"] != "abc" {
+			t.Errorf("payload = %v", payload)
+		}
+		if !strings.Contains(payload["text"].(string), "[test] panic in goroutine") {
+			t.Errorf("text = %v", payload["text"])
+		}
+		if !strings.Contains(payload["stack"].(string), "TestSafeGoWebhook") {
+			t.Errorf("stack missing caller: %v", payload["stack"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := Flush(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReporterPanic(t *testing.T) {
+	called := make(chan struct{})
+	SetReporters(
+		PanicReporterFunc(func(ctx context.Context, p *Panic) error { panic("reporter") }),
+		PanicReporterFunc(func(ctx context.Context, p *Panic) error {
+			close(called)
+			return nil
+		}),
+	)
+	defer SetReporters()
+	Report(context.Background(), NewPanic(context.Background(), SOURCE_TASK, "boom"))
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("second reporter not called")
+	}
+	Flush(context.Background())
+}
+
+func TestContextReporters(t *testing.T) {
+	calls := make(chan string, 4)
+	reporter := func(name string) PanicReporter {
+		return PanicReporterFunc(func(ctx context.Context, p *Panic) error {
+			calls <- name
+			return nil
+		})
+	}
+	SetReporters(reporter("global"))
+	defer SetReporters()
+	ctx := WithReporters(context.Background(), reporter("server"))
+	Report(ctx, NewPanic(ctx, SOURCE_HTTP, "boom"))
+	// 未附加上报器的ctx只通知全局上报器
+	Report(context.Background(), NewPanic(context.Background(), SOURCE_TASK, "boom"))
+	Flush(context.Background())
+	close(calls)
+	got := map[string]int{}
+	for name := range calls {
+		got[name]++
+	}
+	if got["global"] != 2 || got["server"] != 1 {
+		t.Errorf("calls = %v, want global 2, server 1", got)
+	}
+}
@@ -0,0 +1,76 @@
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// WebhookReporter 以JSON POST到webhook，text字段为摘要，可直接用于多数IM机器人
+type WebhookReporter struct {
+	URL     string
+	Header  http.Header
+	Client  *http.Client
+	Service string
+}
+
+// NewWebhookReporter headers用于鉴权等，格式为key=value
+func NewWebhookReporter(url string, service string, headers ...string) *WebhookReporter {
+	header := make(http.Header)
+	for _, h := range headers {
+		if k, v, ok := strings.Cut(h, "="); ok {
+			header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+	return &WebhookReporter{
+		URL:     url,
+		Header:  header,
+		Client:  &http.Client{Timeout: REPORT_TIMEOUT},
+		Service: service,
+	}
+}
+
+type webhookPayload struct {
+	Text    string `json:"text"`
+	Service string `json:"service"`
+	Host    string `json:"host"`
+	*Panic
+}
+
+var hostname, _ = os.Hostname()
+
+func (w *WebhookReporter) Report(ctx context.Context, p *Panic) error {
+	text := fmt.Sprintf("[%s] panic in %s on %s: %s", w.Service, p.Source, hostname, p.Value)
+	if p.Path != "" {
+		text += fmt.Sprintf(" (%s %s)", p.Method, p.Path)
+	}
+	data, err := json.Marshal(webhookPayload{Text: text, Service: w.Service, Host: hostname, Panic: p})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, values := range w.Header {
+		for _, v := range values {
+			request.Header.Add(k, v)
+		}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := w.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return nil
+}
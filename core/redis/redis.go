@@ -21,6 +21,7 @@ func NewRedisClient(redisConfig conf.RedisConfig) (*redis.Client, error) {
 		Password: redisConfig.Password, // no password set
 		DB:       0,                    // use default DB
 	})
+	rdb.AddHook(traceHook{})
 	slog.Info("Init redis,", "addr", redisConfig.Addr)
 
 	_, err := rdb.Ping(context.Background()).Result()
@@ -42,6 +43,7 @@ func NewRedisClusterClient(redisConfig conf.RedisConfig) (*redis.ClusterClient,
 		//RouteByLatency: true,
 		//RouteRandomly: true,
 	})
+	clusterRdb.AddHook(traceHook{})
 	slog.Info("Init redis cluster,", "addr", redisConfig.Addr)
 
 	err := clusterRdb.ForEachShard(context.Background(), func(ctx context.Context, shard *redis.Client) error {
@@ -52,3 +54,31 @@ func NewRedisClusterClient(redisConfig conf.RedisConfig) (*redis.ClusterClient,
 	}
 	return clusterRdb, nil
 }
+
+// Component redis组件，可注册到rest.Server，启动时检查连接，关闭时释放连接，同时作为就绪检查项
+type Component struct {
+	name   string
+	client redis.UniversalClient
+}
+
+// NewComponent client可以是*redis.Client或*redis.ClusterClient
+func NewComponent(name string, client redis.UniversalClient) *Component {
+	return &Component{name: name, client: client}
+}
+
+func (c *Component) Name() string {
+	return c.name
+}
+
+func (c *Component) Start(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *Component) Stop(ctx context.Context) error {
+	return c.client.Close()
+}
+
+// Check 就绪检查，ping redis
+func (c *Component) Check(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
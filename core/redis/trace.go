@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/kappere/go-rest/core/trace"
+)
+
+// traceHook 为每条命令创建span，需通过命令的ctx参数传入请求的context，
+// context中没有trace时不创建span
+type traceHook struct{}
+
+func (traceHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startSpan(ctx, "redis "+cmd.Name()), nil
+}
+
+func (traceHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endSpan(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+func (traceHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return startSpan(ctx, "redis pipeline"), nil
+}
+
+func (traceHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	names := make([]string, 0, len(cmds))
+	var err error
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+		if cmd.Err() != nil && err == nil {
+			err = cmd.Err()
+		}
+	}
+	endSpan(ctx, strings.Join(names, " "), err)
+	return nil
+}
+
+func startSpan(ctx context.Context, name string) context.Context {
+	if trace.FromContext(ctx) == nil {
+		return ctx
+	}
+	ctx, _ = trace.Start(ctx, name, trace.KIND_CLIENT)
+	return ctx
+}
+
+func endSpan(ctx context.Context, statement string, err error) {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return
+	}
+	span.SetAttr("db.system", "redis")
+	span.SetAttr("db.statement", statement)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.SetError(err)
+	}
+	span.End()
+}
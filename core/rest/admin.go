@@ -0,0 +1,208 @@
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/logger"
+	"github.com/kappere/go-rest/core/middleware"
+)
+
+// adminServer 管理端口，提供pprof、运行时统计、GC统计、goroutine dump和构建信息
+type adminServer struct {
+	Engine     *gin.Engine
+	lock       sync.RWMutex
+	token      string
+	httpServer *http.Server
+	listener   net.Listener
+}
+
+func newAdminServer(adminConf conf.AdminConfig, rpcToken string) *adminServer {
+	a := &adminServer{
+		Engine: gin.New(),
+		token:  rpcToken,
+	}
+	a.Engine.Use(middleware.NiceRecovery(), middleware.AdminAuth(adminConf, a.currentToken))
+	debugGroup := a.Engine.Group("/debug")
+	debugGroup.GET("/stat", func(c *gin.Context) {
+		c.JSON(http.StatusOK, httpx.Ok(currentStat()))
+	})
+	debugGroup.GET("/gc", gcStats)
+	debugGroup.GET("/goroutines", goroutineDump)
+	debugGroup.GET("/buildinfo", buildInfo)
+	debugGroup.GET("/loglevel", getLogLevel)
+	debugGroup.PUT("/loglevel", setLogLevel)
+	debugGroup.POST("/loglevel", setLogLevel)
+	pprofGroup := debugGroup.Group("/pprof")
+	pprofGroup.GET("/", gin.WrapF(pprof.Index))
+	pprofGroup.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	pprofGroup.GET("/profile", gin.WrapF(pprof.Profile))
+	pprofGroup.POST("/symbol", gin.WrapF(pprof.Symbol))
+	pprofGroup.GET("/symbol", gin.WrapF(pprof.Symbol))
+	pprofGroup.GET("/trace", gin.WrapF(pprof.Trace))
+	// heap、goroutine、allocs、block、mutex、threadcreate
+	pprofGroup.GET("/:name", func(c *gin.Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+	return a
+}
+
+func (a *adminServer) currentToken() string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.token
+}
+
+// setToken rpc token热更新
+func (a *adminServer) setToken(token string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.token = token
+}
+
+func (a *adminServer) start(ctx context.Context, adminConf conf.AdminConfig) error {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", net.JoinHostPort(adminConf.Host, strconv.Itoa(adminConf.Port)))
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: a.Engine}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error("Admin server failed:", "error", err)
+		}
+	}()
+	a.lock.Lock()
+	a.httpServer = srv
+	a.listener = listener
+	a.lock.Unlock()
+	log.Info("Started admin server [" + listener.Addr().String() + "]")
+	return nil
+}
+
+func (a *adminServer) shutdown(ctx context.Context) error {
+	a.lock.RLock()
+	srv := a.httpServer
+	a.lock.RUnlock()
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+func (a *adminServer) addr() string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if a.listener == nil {
+		return ""
+	}
+	return a.listener.Addr().String()
+}
+
+type gcStat struct {
+	NumGC        int64           `json:"numGC"`
+	LastGC       time.Time       `json:"lastGC"`
+	PauseTotal   time.Duration   `json:"pauseTotal"`
+	RecentPauses []time.Duration `json:"recentPauses"`
+	HeapAlloc    uint64          `json:"heapAlloc"`
+	NextGC       uint64          `json:"nextGC"`
+	GCCPUPercent float64         `json:"gcCPUPercent"`
+}
+
+func gcStats(c *gin.Context) {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	pauses := stats.Pause
+	if len(pauses) > 20 {
+		pauses = pauses[:20]
+	}
+	c.JSON(http.StatusOK, httpx.Ok(gcStat{
+		NumGC:        stats.NumGC,
+		LastGC:       stats.LastGC,
+		PauseTotal:   stats.PauseTotal,
+		RecentPauses: pauses,
+		HeapAlloc:    m.HeapAlloc,
+		NextGC:       m.NextGC,
+		GCCPUPercent: m.GCCPUFraction * 100,
+	}))
+}
+
+// goroutineDump 输出所有goroutine的完整堆栈
+func goroutineDump(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	rpprof.Lookup("goroutine").WriteTo(c.Writer, 2)
+}
+
+type buildInfoResult struct {
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+	Deps      map[string]string `json:"deps"`
+}
+
+func buildInfo(c *gin.Context) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		c.JSON(http.StatusOK, httpx.Error("build info not available"))
+		return
+	}
+	result := buildInfoResult{
+		GoVersion: info.GoVersion,
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+		Settings:  make(map[string]string, len(info.Settings)),
+		Deps:      make(map[string]string, len(info.Deps)),
+	}
+	for _, s := range info.Settings {
+		result.Settings[s.Key] = s.Value
+	}
+	for _, dep := range info.Deps {
+		result.Deps[dep.Path] = dep.Version
+	}
+	c.JSON(http.StatusOK, httpx.Ok(result))
+}
+
+type logLevelResult struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+func getLogLevel(c *gin.Context) {
+	level, modules := logger.Levels()
+	c.JSON(http.StatusOK, httpx.Ok(logLevelResult{Level: level, Modules: modules}))
+}
+
+// setLogLevel 运行时修改日志级别，不会写回配置文件，配置热更新后以配置为准
+//
+//	PUT /debug/loglevel?level=debug             修改全局级别
+//	PUT /debug/loglevel?module=db&level=debug   修改模块级别
+//	PUT /debug/loglevel?module=db               取消模块级别，使用全局级别
+func setLogLevel(c *gin.Context) {
+	module, level := c.Query("module"), c.Query("level")
+	var err error
+	if module == "" {
+		err = logger.SetLevel(level)
+	} else {
+		err = logger.SetModuleLevel(module, level)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, httpx.Error("invalid level: "+level))
+		return
+	}
+	log.Warn("Log level changed.", "module", module, "level", level)
+	getLogLevel(c)
+}
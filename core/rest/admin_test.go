@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/logger"
+)
+
+func TestAdminServer(t *testing.T) {
+	server := newTestServer(t, func(c *conf.HttpConfig) {
+		c.Rpc.Token = "secret"
+		c.Admin = conf.AdminConfig{Enable: true, Host: "127.0.0.1", Port: 0}
+	})
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+
+	get := func(addr, path, token string) (int, string) {
+		t.Helper()
+		request, _ := http.NewRequest(http.MethodGet, "http://"+addr+path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/debug/stat", `"routine"`},
+		{"/debug/gc", `"numGC"`},
+		{"/debug/goroutines", "goroutine "},
+		{"/debug/buildinfo", `"goVersion"`},
+		{"/debug/pprof/", "heap"},
+		{"/debug/pprof/heap?debug=1", "heap profile"},
+		{"/debug/pprof/cmdline", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if code, _ := get(server.AdminAddr(), tt.path, ""); code != http.StatusUnauthorized {
+				t.Errorf("without token = %d, want 401", code)
+			}
+			if code, _ := get(server.AdminAddr(), tt.path, "wrong"); code != http.StatusUnauthorized {
+				t.Errorf("wrong token = %d, want 401", code)
+			}
+			code, body := get(server.AdminAddr(), tt.path, "secret")
+			if code != http.StatusOK || !strings.Contains(body, tt.want) {
+				t.Errorf("with token = %d, body missing %q", code, tt.want)
+			}
+		})
+	}
+
+	// 业务端口不提供管理接口
+	if code, _ := get(server.Addr(), "/debug/stat", "secret"); code != http.StatusNotFound {
+		t.Errorf("public port /debug/stat = %d, want 404", code)
+	}
+}
+
+func TestAdminBasicAuth(t *testing.T) {
+	server := newTestServer(t, func(c *conf.HttpConfig) {
+		c.Admin = conf.AdminConfig{Enable: true, Host: "127.0.0.1", Port: 0, Username: "admin", Password: "pass"}
+	})
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     int
+	}{
+		{"ok", "admin", "pass", http.StatusOK},
+		{"wrong password", "admin", "wrong", http.StatusUnauthorized},
+		{"no auth", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, "http://"+server.AdminAddr()+"/debug/stat", nil)
+			if tt.username != "" {
+				request.SetBasicAuth(tt.username, tt.password)
+			}
+			resp, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminLogLevel(t *testing.T) {
+	server := newTestServer(t, func(c *conf.HttpConfig) {
+		c.Admin = conf.AdminConfig{Enable: true, Host: "127.0.0.1", Port: 0, Username: "admin", Password: "pass"}
+	})
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+	defer func() {
+		logger.SetLevel("info")
+		logger.SetModuleLevels(nil)
+	}()
+
+	tests := []struct {
+		method   string
+		query    string
+		wantCode int
+		want     string
+	}{
+		{http.MethodGet, "", http.StatusOK, `"level":"info"`},
+		{http.MethodPut, "?level=warn", http.StatusOK, `"level":"warn"`},
+		{http.MethodPut, "?module=db&level=debug", http.StatusOK, `"db":"debug"`},
+		{http.MethodPost, "?module=db", http.StatusOK, `"modules":{}`},
+		{http.MethodPut, "?level=verbose", http.StatusBadRequest, "invalid level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+tt.query, func(t *testing.T) {
+			request, _ := http.NewRequest(tt.method, "http://"+server.AdminAddr()+"/debug/loglevel"+tt.query, nil)
+			request.SetBasicAuth("admin", "pass")
+			resp, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantCode || !strings.Contains(string(body), tt.want) {
+				t.Errorf("status = %d, body = %s, want %d %q", resp.StatusCode, body, tt.wantCode, tt.want)
+			}
+		})
+	}
+}
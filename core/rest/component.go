@@ -0,0 +1,211 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 单个组件启动/停止的默认超时
+const DEFAULT_COMPONENT_TIMEOUT = 5 * time.Second
+
+// Component 由Server管理生命周期的组件，如数据库、redis、定时任务。
+// Start在HTTP服务监听前按依赖顺序调用，Stop在HTTP服务关闭后逆序调用。
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Dependent 可选，声明依赖的组件名，被依赖的组件先启动、后停止
+type Dependent interface {
+	DependsOn() []string
+}
+
+// TimeoutComponent 可选，自定义启动/停止超时，默认DEFAULT_COMPONENT_TIMEOUT
+type TimeoutComponent interface {
+	Timeout() time.Duration
+}
+
+// FuncComponent 由函数构造组件，StartFunc/StopFunc可为nil
+type FuncComponent struct {
+	ComponentName string
+	StartFunc     func(ctx context.Context) error
+	StopFunc      func(ctx context.Context) error
+	Deps          []string
+	// StopTimeout 为0时使用DEFAULT_COMPONENT_TIMEOUT
+	StopTimeout time.Duration
+}
+
+func (c *FuncComponent) Name() string {
+	return c.ComponentName
+}
+
+func (c *FuncComponent) Start(ctx context.Context) error {
+	if c.StartFunc == nil {
+		return nil
+	}
+	return c.StartFunc(ctx)
+}
+
+func (c *FuncComponent) Stop(ctx context.Context) error {
+	if c.StopFunc == nil {
+		return nil
+	}
+	return c.StopFunc(ctx)
+}
+
+func (c *FuncComponent) DependsOn() []string {
+	return c.Deps
+}
+
+func (c *FuncComponent) Timeout() time.Duration {
+	if c.StopTimeout > 0 {
+		return c.StopTimeout
+	}
+	return DEFAULT_COMPONENT_TIMEOUT
+}
+
+// lifecycle 组件注册表
+type lifecycle struct {
+	lock       sync.Mutex
+	components []Component
+	// 已启动的组件，按启动顺序
+	started []Component
+}
+
+func (l *lifecycle) register(components ...Component) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.components = append(l.components, components...)
+}
+
+// addStarted 注册已处于运行状态的组件（如AddClose），只参与停止
+func (l *lifecycle) addStarted(component Component) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.started = append(l.started, component)
+}
+
+// start 按依赖顺序启动未启动的组件，任一组件失败时逆序停止已启动的组件
+func (l *lifecycle) start(ctx context.Context) error {
+	l.lock.Lock()
+	components := l.components
+	l.components = nil
+	l.lock.Unlock()
+
+	ordered, err := sortComponents(components)
+	if err != nil {
+		return err
+	}
+	for _, component := range ordered {
+		begin := time.Now()
+		err := runWithTimeout(ctx, component, component.Start)
+		if err != nil {
+			err = fmt.Errorf("start component %s: %w", component.Name(), err)
+			if stopErr := l.stop(context.Background()); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+			return err
+		}
+		l.addStarted(component)
+		log.Info(fmt.Sprintf("[component] %s started in %.3f seconds", component.Name(), time.Since(begin).Seconds()))
+	}
+	return nil
+}
+
+// stop 逆序停止已启动的组件，单个组件超时或失败不影响其余组件，返回所有错误的聚合
+func (l *lifecycle) stop(ctx context.Context) error {
+	l.lock.Lock()
+	started := l.started
+	l.started = nil
+	l.lock.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		component := started[i]
+		begin := time.Now()
+		if err := runWithTimeout(ctx, component, component.Stop); err != nil {
+			log.Error("[component] "+component.Name()+" stop failed.", "error", err)
+			errs = append(errs, fmt.Errorf("stop component %s: %w", component.Name(), err))
+			continue
+		}
+		log.Info(fmt.Sprintf("[component] %s stopped in %.3f seconds", component.Name(), time.Since(begin).Seconds()))
+	}
+	return errors.Join(errs...)
+}
+
+// runWithTimeout 在组件超时内执行f，f未按时返回时不再等待
+func runWithTimeout(ctx context.Context, component Component, f func(ctx context.Context) error) error {
+	timeout := DEFAULT_COMPONENT_TIMEOUT
+	if t, ok := component.(TimeoutComponent); ok && t.Timeout() > 0 {
+		timeout = t.Timeout()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- f(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sortComponents 按依赖拓扑排序，无依赖关系的组件保持注册顺序
+func sortComponents(components []Component) ([]Component, error) {
+	byName := make(map[string]Component, len(components))
+	for _, component := range components {
+		if _, ok := byName[component.Name()]; ok {
+			return nil, fmt.Errorf("duplicate component %s", component.Name())
+		}
+		byName[component.Name()] = component
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(components))
+	ordered := make([]Component, 0, len(components))
+	var visit func(component Component, path []string) error
+	visit = func(component Component, path []string) error {
+		name := component.Name()
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("component dependency cycle: %v", append(path, name))
+		}
+		state[name] = visiting
+		if d, ok := component.(Dependent); ok {
+			for _, dep := range d.DependsOn() {
+				depComponent, ok := byName[dep]
+				if !ok {
+					return fmt.Errorf("component %s depends on unknown component %s", name, dep)
+				}
+				if err := visit(depComponent, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, component)
+		return nil
+	}
+	for _, component := range components {
+		if err := visit(component, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestComponent(name string, events *[]string, stopErr error, deps ...string) *FuncComponent {
+	return &FuncComponent{
+		ComponentName: name,
+		StartFunc: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return nil
+		},
+		StopFunc: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return stopErr
+		},
+		Deps: deps,
+	}
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	var events []string
+	var l lifecycle
+	l.register(
+		newTestComponent("service", &events, nil, "db", "redis"),
+		newTestComponent("db", &events, nil),
+		newTestComponent("redis", &events, errors.New("close redis failed")),
+	)
+	if err := l.start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	err := l.stop(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "close redis failed") {
+		t.Errorf("stop error = %v, want redis error", err)
+	}
+	want := []string{"start db", "start redis", "start service", "stop service", "stop redis", "stop db"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	// 重复停止不再执行
+	if err := l.stop(context.Background()); err != nil || len(events) != len(want) {
+		t.Errorf("second stop: err = %v, events = %v", err, events)
+	}
+}
+
+func TestLifecycleStartFailed(t *testing.T) {
+	var events []string
+	var l lifecycle
+	failed := newTestComponent("cache", &events, nil, "db")
+	failed.StartFunc = func(ctx context.Context) error {
+		return errors.New("boom")
+	}
+	l.register(newTestComponent("db", &events, nil), failed)
+	err := l.start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start component cache: boom") {
+		t.Errorf("start error = %v", err)
+	}
+	want := []string{"start db", "stop db"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestLifecycleStopTimeout(t *testing.T) {
+	var events []string
+	var l lifecycle
+	slow := newTestComponent("slow", &events, nil)
+	slow.StopTimeout = 50 * time.Millisecond
+	slow.StopFunc = func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	l.register(newTestComponent("db", &events, nil), slow)
+	if err := l.start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	begin := time.Now()
+	err := l.stop(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stop error = %v, want deadline exceeded", err)
+	}
+	if time.Since(begin) > 500*time.Millisecond {
+		t.Errorf("stop took %v, slow component should time out", time.Since(begin))
+	}
+	if events[len(events)-1] != "stop db" {
+		t.Errorf("events = %v, db should stop after slow timed out", events)
+	}
+}
+
+func TestSortComponentsError(t *testing.T) {
+	var events []string
+	tests := []struct {
+		name       string
+		components []Component
+		want       string
+	}{
+		{
+			name: "cycle",
+			components: []Component{
+				newTestComponent("a", &events, nil, "b"),
+				newTestComponent("b", &events, nil, "a"),
+			},
+			want: "cycle",
+		},
+		{
+			name:       "unknown",
+			components: []Component{newTestComponent("a", &events, nil, "x")},
+			want:       "unknown component x",
+		},
+		{
+			name: "duplicate",
+			components: []Component{
+				newTestComponent("a", &events, nil),
+				newTestComponent("a", &events, nil),
+			},
+			want: "duplicate component a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sortComponents(tt.components)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/middleware"
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	// Field 字段路径，使用json/uri/form/header标签名，如user.name
+	Field string `json:"field"`
+	// Rule 未通过的规则，如required、min
+	Rule string `json:"rule"`
+	// Param 规则参数，如min=3中的3
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors 参数校验失败时作为响应的data返回
+type ValidationErrors struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (v *ValidationErrors) Error() string {
+	messages := make([]string, len(v.Errors))
+	for i, e := range v.Errors {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// 使用gin的binding标签，字段名取自json/uri/form/header标签
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "uri", "form", "header"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return v
+}
+
+type ginContextKey struct{}
+
+// GinContext 在Handle的处理函数中获取gin.Context，用于设置响应头、cookie等
+func GinContext(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c
+}
+
+// Handle 将类型化的处理函数适配为gin.HandlerFunc。
+// 请求依次绑定路径参数(uri标签)、查询参数和表单(form标签)、请求头(header标签)和JSON请求体(json标签)，
+// 每个来源只绑定带有其标签的字段，没有任何来源标签的字段只从JSON请求体绑定；然后按binding标签校验，校验失败返回STATUS_ERROR_PARAM和各字段错误。
+// 处理函数返回的结果以当前请求的响应格式(参见middleware.ResponseFormat)包装；返回的错误经httpx.AsBizError转换后由middleware.AbortWithError渲染，
+// 与NiceRecovery处理panic(*httpx.BizError)一致。错误带有Cause时记录日志。
+//
+//	type FindUserReq struct {
+//		Id    int64  `uri:"id" binding:"required,min=1"`
+//		Token string `header:"X-Token"`
+//	}
+//	group.GET("/user/:id", rest.Handle(func(ctx context.Context, req FindUserReq) (model.User, error) {
+//		return svc.FindUser(ctx, req.Id)
+//	}))
+func Handle[Req any, Resp any](f func(ctx context.Context, req Req) (Resp, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Req
+		if err := bind(c, &req); err != nil {
+			var validationErrors *ValidationErrors
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				// middleware.MaxBytes限制的未知长度请求体
+				middleware.AbortWithError(c, httpx.ErrTooLarge.WithMessage("Request body too large"))
+				return
+			}
+			if errors.As(err, &validationErrors) {
+				middleware.AbortWithError(c, httpx.ErrParam.WithDetails(validationErrors))
+				return
+			}
+			middleware.AbortWithError(c, httpx.ErrParam.WithMessage(err.Error()))
+			return
+		}
+		ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
+		resp, err := f(ctx, req)
+		if err != nil {
+			bizError := httpx.AsBizError(err)
+			if bizError.Cause != nil {
+				log.ErrorContext(ctx, "Request failed.", "path", c.FullPath(), "code", bizError.Code, "error", bizError.Error())
+			}
+			middleware.AbortWithError(c, bizError)
+			return
+		}
+		middleware.RenderOk(c, resp)
+	}
+}
+
+// bind 绑定并校验请求，Req为指针类型时自动创建
+func bind(c *gin.Context, ptr any) error {
+	v := reflect.ValueOf(ptr).Elem()
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		ptr = v.Interface()
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("request type %s must be a struct", v.Type())
+	}
+	if len(c.Params) > 0 {
+		params := make(map[string][]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := mapSource(v, ptr, params, "uri"); err != nil {
+			return err
+		}
+	}
+	form, err := formValues(c)
+	if err != nil {
+		return err
+	}
+	// 没有参数时也需要映射，以设置form标签中的default
+	if err := mapSource(v, ptr, form, "form"); err != nil {
+		return err
+	}
+	if len(c.Request.Header) > 0 {
+		// 同时支持规范形式和小写的请求头标签，如X-Token和x-token
+		headers := make(map[string][]string, len(c.Request.Header)*2)
+		for k, values := range c.Request.Header {
+			headers[k] = values
+			headers[strings.ToLower(k)] = values
+		}
+		if err := mapSource(v, ptr, headers, "header"); err != nil {
+			return err
+		}
+	}
+	if c.Request.Body != nil && c.Request.Body != http.NoBody && c.ContentType() == binding.MIMEJSON {
+		if err := decodeJSON(c.Request.Body, v, ptr); err != nil {
+			return fmt.Errorf("invalid json body: %w", err)
+		}
+	}
+	return validateStruct(ptr)
+}
+
+var sourceTags = []string{"uri", "form", "header", "json"}
+
+// mapSource 只将一个来源映射到带有其标签的字段。gin会按字段名匹配没有该标签的字段，
+// 如?Id=1或Id请求头会覆盖uri:"id"的Id，因此只保留标签中声明的键，并在映射时保护其他字段
+func mapSource(v reflect.Value, ptr any, values map[string][]string, tag string) error {
+	names := make(map[string]bool)
+	collectTagNames(v.Type(), tag, names, make(map[reflect.Type]bool))
+	filtered := make(map[string][]string, len(values))
+	for k, vs := range values {
+		if names[k] {
+			filtered[k] = vs
+		}
+	}
+	saved := protectFields(v, tag, nil)
+	err := binding.MapFormWithTag(ptr, filtered, tag)
+	for _, s := range saved {
+		s.field.Set(s.value)
+	}
+	return err
+}
+
+// collectTagNames 收集t及其嵌套结构体中tag声明的键名
+func collectTagNames(t reflect.Type, tag string, names map[string]bool, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			names[name] = true
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			collectTagNames(ft, tag, names, visited)
+		}
+	}
+}
+
+type savedField struct {
+	field reflect.Value
+	value reflect.Value
+}
+
+// protectFields 置零并收集不带tag标签的字段，映射后恢复；没有来源标签的结构体字段逐个检查其中的字段
+func protectFields(v reflect.Value, tag string, saved []savedField) []savedField {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get(tag) != "" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		field := v.Field(i)
+		if !hasSourceTag(sf) {
+			if field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct {
+				// 为nil时由gin按需创建
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct && hasExportedField(field.Type()) {
+				saved = protectFields(field, tag, saved)
+				continue
+			}
+		}
+		if !field.CanSet() {
+			continue
+		}
+		value := reflect.New(field.Type()).Elem()
+		value.Set(field)
+		field.Set(reflect.Zero(field.Type()))
+		saved = append(saved, savedField{field, value})
+	}
+	return saved
+}
+
+func hasSourceTag(sf reflect.StructField) bool {
+	for _, tag := range sourceTags {
+		if sf.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasExportedField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeJSON 解码JSON请求体。encoding/json按字段名匹配没有json标签的字段（不区分大小写），
+// 如{"Id":1}会覆盖uri:"id"的Id，因此丢弃只与其他来源字段匹配的顶层键
+func decodeJSON(r io.Reader, v reflect.Value, ptr any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(data, &object) == nil && object != nil {
+		allowed, protected := make(map[string]bool), make(map[string]bool)
+		collectJSONNames(v.Type(), allowed, protected)
+		changed := false
+		for k := range object {
+			if !allowed[k] && protected[strings.ToLower(k)] {
+				delete(object, k)
+				changed = true
+			}
+		}
+		if changed {
+			if data, err = json.Marshal(object); err != nil {
+				return err
+			}
+		}
+	}
+	return json.Unmarshal(data, ptr)
+}
+
+// collectJSONNames 收集顶层（含匿名嵌入结构体提升的）字段的JSON键名，
+// protected为带其他来源标签而没有json标签的字段名（小写）
+func collectJSONNames(t reflect.Type, allowed, protected map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case name == "-":
+		case name != "":
+			allowed[name] = true
+		case sf.Anonymous && ft.Kind() == reflect.Struct:
+			collectJSONNames(ft, allowed, protected)
+		case !sf.IsExported():
+		case hasSourceTag(sf):
+			protected[strings.ToLower(sf.Name)] = true
+		default:
+			allowed[sf.Name] = true
+		}
+	}
+}
+
+// formValues 查询参数和表单，form标签
+func formValues(c *gin.Context) (map[string][]string, error) {
+	switch c.ContentType() {
+	case binding.MIMEPOSTForm:
+		if err := c.Request.ParseForm(); err != nil {
+			return nil, err
+		}
+		return c.Request.Form, nil
+	case binding.MIMEMultipartPOSTForm:
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+		return c.Request.Form, nil
+	}
+	return c.Request.URL.Query(), nil
+}
+
+func validateStruct(ptr any) error {
+	err := validate.Struct(ptr)
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+	result := &ValidationErrors{Errors: make([]FieldError, 0, len(fieldErrors))}
+	for _, fe := range fieldErrors {
+		// Namespace为Req.user.name，去掉结构体名
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		result.Errors = append(result.Errors, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(field, fe.Tag(), fe.Param()),
+		})
+	}
+	return result
+}
+
+func fieldMessage(field, rule, param string) string {
+	switch rule {
+	case "required":
+		return field + " is required"
+	case "min", "gte":
+		return field + " must be at least " + param
+	case "max", "lte":
+		return field + " must be at most " + param
+	case "len":
+		return field + " must have length " + param
+	case "oneof":
+		return field + " must be one of [" + param + "]"
+	}
+	if param != "" {
+		return field + " must satisfy " + rule + "=" + param
+	}
+	return field + " must be a valid " + rule
+}
@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/middleware"
+)
+
+type testUserReq struct {
+	Id      int64    `uri:"id" binding:"required,min=1"`
+	Fields  []string `form:"fields"`
+	Token   string   `header:"X-Token" binding:"required"`
+	Name    string   `json:"name" binding:"required,max=5"`
+	Profile struct {
+		Email string `json:"email" binding:"omitempty,email"`
+	} `json:"profile"`
+}
+
+type testUserResp struct {
+	Id     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+	Token  string   `json:"token"`
+}
+
+type codeError struct{ code int }
+
+func (e codeError) Error() string { return "user not found" }
+func (e codeError) Code() int     { return e.code }
+
+func TestHandle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/user/:id", Handle(func(ctx context.Context, req testUserReq) (testUserResp, error) {
+		if GinContext(ctx) == nil {
+			return testUserResp{}, errors.New("gin context missing")
+		}
+		if req.Id == 404 {
+			return testUserResp{}, codeError{-404}
+		}
+		if req.Id == 403 {
+			return testUserResp{}, fmt.Errorf("find user: %w", httpx.ErrNoAuthorization.WithDetails(map[string]int64{"id": req.Id}))
+		}
+		return testUserResp{Id: req.Id, Name: req.Name, Fields: req.Fields, Token: req.Token}, nil
+	}))
+	engine.GET("/ptr", Handle(func(ctx context.Context, req *struct {
+		Page int `form:"page,default=1"`
+	}) (int, error) {
+		return req.Page, nil
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   string
+	}{
+		{"bind all sources", http.MethodPost, "/user/7?fields=a&fields=b", "t1", `{"name":"tom"}`,
+			`{"code":0,"message":"","data":{"id":7,"name":"tom","fields":["a","b"],"token":"t1"}}`},
+		{"validation errors", http.MethodPost, "/user/0", "", `{"name":"too long","profile":{"email":"x"}}`,
+			`{"code":-896,"message":"invalid parameters","data":{"errors":[` +
+				`{"field":"id","rule":"required","message":"id is required"},` +
+				`{"field":"X-Token","rule":"required","message":"X-Token is required"},` +
+				`{"field":"name","rule":"max","param":"5","message":"name must be at most 5"},` +
+				`{"field":"profile.email","rule":"email","message":"profile.email must be a valid email"}]}}`},
+		{"bad json", http.MethodPost, "/user/1", "t1", `{"name":`, `"code":-896,"message":"invalid json body`},
+		{"bad uri", http.MethodPost, "/user/abc", "t1", `{"name":"tom"}`, `"code":-896`},
+		{"error code", http.MethodPost, "/user/404", "t1", `{"name":"tom"}`, `{"code":-404,"message":"user not found","data":null}`},
+		{"biz error", http.MethodPost, "/user/403", "t1", `{"name":"tom"}`, `{"code":-989,"message":"authorization required","data":{"id":403}}`},
+		{"pointer request", http.MethodGet, "/ptr", "", "", `{"code":0,"message":"","data":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+			if tt.token != "" {
+				request.Header.Set("X-Token", tt.token)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, request)
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want %s", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleSourceOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Handle(http.MethodGet, "/user/:id", Handle(func(ctx context.Context, req testUserReq) (testUserResp, error) {
+		return testUserResp{Id: req.Id, Name: req.Name, Fields: req.Fields, Token: req.Token}, nil
+	}))
+	engine.Handle(http.MethodPost, "/user/:id", Handle(func(ctx context.Context, req testUserReq) (testUserResp, error) {
+		return testUserResp{Id: req.Id, Name: req.Name, Fields: req.Fields, Token: req.Token}, nil
+	}))
+	want := `{"code":0,"message":"","data":{"id":7,"name":"tom","fields":["a"],"token":"t1"}}`
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header map[string]string
+		body   string
+	}{
+		{"query", http.MethodGet, "/user/7?fields=a&Id=999&Token=t2&Name=jerry&name=jerry", map[string]string{"Name": "jerry"}, ""},
+		{"header", http.MethodGet, "/user/7?fields=a", map[string]string{"Id": "1000", "Fields": "b"}, ""},
+		{"invalid header", http.MethodGet, "/user/7?fields=a", map[string]string{"Id": "abc"}, ""},
+		{"body", http.MethodPost, "/user/7?fields=a", nil, `{"name":"tom","Id":1000,"Fields":["b"],"Token":"t2"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" {
+				body = `{"name":"tom"}`
+			}
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("X-Token", "t1")
+			for k, v := range tt.header {
+				request.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, request)
+			if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != want {
+				t.Errorf("response = %d %s, want %s", w.Code, w.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestHandleMaxBytes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.MaxBytes(16))
+	engine.POST("/user", Handle(func(ctx context.Context, req struct {
+		Name string `json:"name"`
+	}) (string, error) {
+		return req.Name, nil
+	}))
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"within limit", `{"name":"tom"}`, `{"code":0,"message":"","data":"tom"}`},
+		{"chunked over limit", `{"name":"tom and jerry"}`, `"code":-898`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			// 未知长度，跳过Content-Length预检
+			request.ContentLength = -1
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, request)
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want %s", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HEALTHZ_PATH = "/healthz"
+	READYZ_PATH  = "/readyz"
+)
+
+// 单个健康检查的超时
+const HEALTH_CHECK_TIMEOUT = 2 * time.Second
+
+// HealthChecker 就绪检查项，Check返回nil表示可用。
+// 实现了HealthChecker的组件注册到Server时自动加入就绪检查。
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// HealthCheckerFunc 由函数构造检查项
+type HealthCheckerFunc struct {
+	CheckerName string
+	CheckFunc   func(ctx context.Context) error
+}
+
+func (c HealthCheckerFunc) Name() string {
+	return c.CheckerName
+}
+
+func (c HealthCheckerFunc) Check(ctx context.Context) error {
+	return c.CheckFunc(ctx)
+}
+
+type healthResult struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// health 健康状态：/healthz为存活检查，进程能响应即返回200；
+// /readyz为就绪检查，服务未启动、正在关闭或任一检查项失败时返回503
+type health struct {
+	lock     sync.RWMutex
+	checkers []HealthChecker
+	ready    atomic.Bool
+}
+
+func (h *health) add(checkers ...HealthChecker) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.checkers = append(h.checkers, checkers...)
+}
+
+func (h *health) setReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// check 并发执行所有检查项
+func (h *health) check(ctx context.Context) healthResult {
+	h.lock.RLock()
+	checkers := h.checkers
+	h.lock.RUnlock()
+
+	result := healthResult{Status: "ok", Checks: make(map[string]string, len(checkers))}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range checkers {
+		wg.Add(1)
+		go func(checker HealthChecker) {
+			defer wg.Done()
+			status := "ok"
+			if err := runCheck(ctx, checker); err != nil {
+				status = err.Error()
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			result.Checks[checker.Name()] = status
+			if status != "ok" {
+				result.Status = "fail"
+			}
+		}(checker)
+	}
+	wg.Wait()
+	if !h.ready.Load() {
+		result.Status = "unavailable"
+	}
+	return result
+}
+
+func runCheck(ctx context.Context, checker HealthChecker) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, HEALTH_CHECK_TIMEOUT)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *health) liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResult{Status: "ok"})
+}
+
+func (h *health) readiness(c *gin.Context) {
+	result := h.check(c.Request.Context())
+	status := http.StatusOK
+	if result.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, result)
+}
+
+func healthRouter(engine *gin.Engine, h *health) {
+	engine.GET(HEALTHZ_PATH, h.liveness)
+	engine.GET(READYZ_PATH, h.readiness)
+}
@@ -1,32 +1,77 @@
 package rest
 
 import (
+	"context"
 	"fmt"
-	"log/slog"
 	"math"
 	"runtime"
 	"time"
+
+	"github.com/kappere/go-rest/core/tool/load"
 )
 
-func setupMonitor() {
-	// 监控服务信息
+// setupMonitor 每60秒输出服务信息，ctx结束时停止
+func setupMonitor(ctx context.Context, sheddingStat *load.SheddingStat) {
 	go func() {
+		var prevStat Stat
+		var prevShedding sheddingLoads
+		ticker := time.NewTicker(60 * time.Second)
+		defer ticker.Stop()
 		for {
-			collectStatisticInfo()
-			time.Sleep(60 * time.Second)
+			prevStat = collectStatisticInfo(prevStat)
+			if sheddingStat != nil {
+				prevShedding = collectSheddingInfo(sheddingStat, prevShedding)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
 		}
 	}()
 }
 
+type sheddingLoads struct {
+	total, pass, drop int64
+}
+
+// collectSheddingInfo 输出最近一个周期的降载统计，有丢弃时以WARN级别输出
+func collectSheddingInfo(stat *load.SheddingStat, prev sheddingLoads) sheddingLoads {
+	var current sheddingLoads
+	current.total, current.pass, current.drop = stat.Loads()
+	total, drop := current.total-prev.total, current.drop-prev.drop
+	if total == 0 {
+		return current
+	}
+	msg := fmt.Sprintf("Shedding: cpu=%d, total=%d, pass=%d, drop=%d", load.CpuUsage(), total, current.pass-prev.pass, drop)
+	if drop > 0 {
+		log.Warn(msg)
+	} else {
+		log.Info(msg)
+	}
+	return current
+}
+
 type Stat struct {
-	Routine int
-	Memory  uint64
-	Heap    uint64
-	Stack   uint64
-	Time    time.Time
+	Routine int       `json:"routine"`
+	Memory  uint64    `json:"memory"`
+	Heap    uint64    `json:"heap"`
+	Stack   uint64    `json:"stack"`
+	Time    time.Time `json:"time"`
 }
 
-var prevStat Stat
+// currentStat 当前运行时统计
+func currentStat() Stat {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return Stat{
+		Routine: runtime.NumGoroutine(),
+		Memory:  m.Sys,
+		Heap:    m.HeapSys,
+		Stack:   m.StackSys,
+		Time:    time.Now(),
+	}
+}
 
 const STAT_THRESHOLD float64 = 0.1
 
@@ -37,27 +82,22 @@ func (s Stat) statExpire(currentStat Stat) bool {
 		math.Abs(float64(s.Stack)-float64(currentStat.Stack))/float64(s.Stack) > STAT_THRESHOLD)
 }
 
-func collectStatisticInfo() {
+// collectStatisticInfo 与上次输出的统计相比变化较大时输出，返回最近一次输出的统计
+func collectStatisticInfo(prevStat Stat) (result Stat) {
+	result = prevStat
 	defer func() {
 		if err := recover(); err != nil {
-			slog.Error("Collect statistic info failed:", "error", err)
+			log.Error("Collect statistic info failed:", "error", err)
 		}
 	}()
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	currentStat := Stat{
-		Routine: runtime.NumGoroutine(),
-		Memory:  m.Sys,
-		Heap:    m.HeapSys,
-		Stack:   m.StackSys,
-		Time:    time.Now(),
-	}
+	currentStat := currentStat()
 	if prevStat.statExpire(currentStat) {
-		prevStat = currentStat
-		slog.Info(fmt.Sprintf("Stat: num_goroutine=%d, memory=%dm, heap=%dm, stack=%dm",
+		result = currentStat
+		log.Info(fmt.Sprintf("Stat: num_goroutine=%d, memory=%dm, heap=%dm, stack=%dm",
 			currentStat.Routine,
 			currentStat.Memory/1024/1024,
 			currentStat.Heap/1024/1024,
 			currentStat.Stack/1024/1024))
 	}
+	return result
 }
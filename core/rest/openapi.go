@@ -0,0 +1,727 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config"
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/middleware"
+	"github.com/kappere/go-rest/core/rpc"
+)
+
+// 鉴权方案，对应OpenAPI文档components.securitySchemes中的名称
+const (
+	SECURITY_JWT    = "jwt"
+	SECURITY_OAUTH2 = "oauth2"
+	SECURITY_RPC    = "rpc"
+)
+
+// 鉴权失败时返回的状态码
+var securityCodes = map[string][]int{
+	SECURITY_JWT:    {httpx.STATUS_NO_AUTHENTICATION},
+	SECURITY_OAUTH2: {httpx.STATUS_NO_AUTHORIZATION},
+	SECURITY_RPC:    {httpx.STATUS_RPC_TIMESTAMP, httpx.STATUS_RPC_TOKEN},
+}
+
+// apiRoute 通过Route注册的路由
+type apiRoute struct {
+	method      string
+	path        string
+	req         reflect.Type
+	resp        reflect.Type
+	operationId string
+	summary     string
+	description string
+	tags        []string
+	security    []string
+	codes       map[int]string
+	deprecated  bool
+	format      httpx.Response
+}
+
+// RouteOption 补充路由在OpenAPI文档中的说明
+type RouteOption func(*apiRoute)
+
+func Summary(summary string) RouteOption {
+	return func(r *apiRoute) { r.summary = summary }
+}
+
+func Description(description string) RouteOption {
+	return func(r *apiRoute) { r.description = description }
+}
+
+// Tags 分组，默认为路径的第一段
+func Tags(tags ...string) RouteOption {
+	return func(r *apiRoute) { r.tags = tags }
+}
+
+// OperationId 默认由方法和路径生成，如GET /user/:id为getUserId
+func OperationId(id string) RouteOption {
+	return func(r *apiRoute) { r.operationId = id }
+}
+
+// ErrorCode 声明处理函数可能返回的业务状态码，description为空时使用httpx.RegisterCode注册的提示
+func ErrorCode(code int, description string) RouteOption {
+	return func(r *apiRoute) { r.codes[code] = description }
+}
+
+// Security 声明单个路由的鉴权方案，路由组的鉴权使用Group.Secure
+func Security(schemes ...string) RouteOption {
+	return func(r *apiRoute) { r.security = appendUnique(r.security, schemes...) }
+}
+
+func Deprecated() RouteOption {
+	return func(r *apiRoute) { r.deprecated = true }
+}
+
+// Group 路由组，通过Route注册的路由记录在所属Server的OpenAPI文档中，
+// 并带有路由组通过Secure声明的鉴权方案和通过ResponseFormat设置的响应格式
+//
+//	group := server.Group("/user").Secure(rest.SECURITY_JWT, middleware.JwtAuth(nil))
+//	rest.GET(group, "/:id", svc.FindUser, rest.Summary("find user by id"))
+type Group struct {
+	*gin.RouterGroup
+	server   *Server
+	security []string
+	format   httpx.Response
+}
+
+// Group 创建路由组
+func (s *Server) Group(relativePath string, handlers ...gin.HandlerFunc) *Group {
+	return &Group{RouterGroup: s.Engine.Group(relativePath, handlers...), server: s}
+}
+
+// RpcGroup 创建rpc路由组，等同于rpc.Server，文档中标记为SECURITY_RPC
+func (s *Server) RpcGroup() *Group {
+	return s.Group(rpc.RPC_PREFIX).Secure(SECURITY_RPC, middleware.Rpc(s.Config.Http.Rpc))
+}
+
+// Group 创建子路由组，继承鉴权方案和响应格式
+func (g *Group) Group(relativePath string, handlers ...gin.HandlerFunc) *Group {
+	return &Group{
+		RouterGroup: g.RouterGroup.Group(relativePath, handlers...),
+		server:      g.server,
+		security:    append([]string(nil), g.security...),
+		format:      g.format,
+	}
+}
+
+// Secure 添加鉴权中间件auth，路由组中之后注册的路由在文档中标记为scheme鉴权，如SECURITY_JWT
+func (g *Group) Secure(scheme string, auth gin.HandlerFunc) *Group {
+	g.Use(auth)
+	g.security = appendUnique(g.security, scheme)
+	return g
+}
+
+// ResponseFormat 使用middleware.ResponseFormat设置路由组的响应格式，文档按该格式生成响应结构，需在Secure之前调用
+func (g *Group) ResponseFormat(format httpx.Response) *Group {
+	g.Use(middleware.ResponseFormat(format))
+	g.format = format
+	return g
+}
+
+// Route 使用Handle注册类型化的处理函数，同时记录请求和响应类型用于生成OpenAPI文档。
+//
+//	group := server.Group("/user").Secure(rest.SECURITY_JWT, middleware.JwtAuth(nil))
+//	rest.GET(group, "/:id", svc.FindUser, rest.Summary("find user by id"))
+func Route[Req any, Resp any](group *Group, method, relativePath string, f func(ctx context.Context, req Req) (Resp, error), options ...RouteOption) gin.IRoutes {
+	route := &apiRoute{
+		method:   strings.ToUpper(method),
+		path:     joinPath(group.BasePath(), relativePath),
+		req:      reflect.TypeOf((*Req)(nil)).Elem(),
+		resp:     reflect.TypeOf((*Resp)(nil)).Elem(),
+		security: append([]string(nil), group.security...),
+		codes:    make(map[int]string),
+		format:   group.format,
+	}
+	for _, option := range options {
+		option(route)
+	}
+	server := group.server
+	server.apiRoutesLock.Lock()
+	server.apiRoutes[route.method+" "+route.path] = route
+	server.apiRoutesLock.Unlock()
+	return group.Handle(route.method, relativePath, Handle(f))
+}
+
+func GET[Req any, Resp any](group *Group, relativePath string, f func(ctx context.Context, req Req) (Resp, error), options ...RouteOption) gin.IRoutes {
+	return Route(group, http.MethodGet, relativePath, f, options...)
+}
+
+func POST[Req any, Resp any](group *Group, relativePath string, f func(ctx context.Context, req Req) (Resp, error), options ...RouteOption) gin.IRoutes {
+	return Route(group, http.MethodPost, relativePath, f, options...)
+}
+
+func PUT[Req any, Resp any](group *Group, relativePath string, f func(ctx context.Context, req Req) (Resp, error), options ...RouteOption) gin.IRoutes {
+	return Route(group, http.MethodPut, relativePath, f, options...)
+}
+
+func DELETE[Req any, Resp any](group *Group, relativePath string, f func(ctx context.Context, req Req) (Resp, error), options ...RouteOption) gin.IRoutes {
+	return Route(group, http.MethodDelete, relativePath, f, options...)
+}
+
+func PATCH[Req any, Resp any](group *Group, relativePath string, f func(ctx context.Context, req Req) (Resp, error), options ...RouteOption) gin.IRoutes {
+	return Route(group, http.MethodPatch, relativePath, f, options...)
+}
+
+func joinPath(base, relativePath string) string {
+	if relativePath == "" {
+		return base
+	}
+	joined := path.Join(base, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+func appendUnique(s []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, e := range s {
+			found = found || e == v
+		}
+		if !found {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+// OpenAPI 根据Route注册到本服务的路由生成OpenAPI 3文档
+func (s *Server) OpenAPI() ([]byte, error) {
+	s.apiRoutesLock.Lock()
+	routes := make([]*apiRoute, 0, len(s.apiRoutes))
+	for _, route := range s.apiRoutes {
+		routes = append(routes, route)
+	}
+	s.apiRoutesLock.Unlock()
+	return json.MarshalIndent(buildOpenAPI(s.Config, routes), "", "  ")
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       openAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components openAPIComponents                `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]any `json:"schemas,omitempty"`
+	SecuritySchemes map[string]any `json:"securitySchemes,omitempty"`
+}
+
+type operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   map[string]any `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema map[string]any `json:"schema"`
+}
+
+func buildOpenAPI(baseConfig config.BaseConfig, routes []*apiRoute) *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: baseConfig.App.Name, Version: openAPIVersion(baseConfig.Http.OpenApi.Version)},
+		Paths:   make(map[string]map[string]*operation),
+	}
+	schemas := newSchemaBuilder()
+	securitySchemes := make(map[string]any)
+	for _, route := range routes {
+		op := &operation{
+			OperationId: route.operationId,
+			Summary:     route.summary,
+			Description: route.description,
+			Tags:        route.tags,
+			Deprecated:  route.deprecated,
+		}
+		if op.OperationId == "" {
+			op.OperationId = operationId(route.method, route.path)
+		}
+		if len(op.Tags) == 0 {
+			if first, _, _ := strings.Cut(strings.TrimPrefix(route.path, "/"), "/"); first != "" && !strings.HasPrefix(first, ":") {
+				op.Tags = []string{first}
+			}
+		}
+		op.Parameters, op.RequestBody = schemas.request(route.method, route.req)
+
+		// 服务端中间件和鉴权可能返回的状态码
+		codes := map[int]string{httpx.STATUS_SUCCESS: "", httpx.STATUS_ERROR_COMMON: ""}
+		if op.Parameters != nil || op.RequestBody != nil {
+			codes[httpx.STATUS_ERROR_PARAM] = ""
+		}
+		if baseConfig.Http.PeriodLimit.Enable {
+			codes[httpx.STATUS_ERROR_LIMIT] = ""
+		}
+		if baseConfig.Http.CpuThreshold > 0 {
+			codes[httpx.STATUS_ERROR_UNAVAILABLE] = ""
+		}
+		if baseConfig.Http.MaxBytes > 0 {
+			codes[httpx.STATUS_ERROR_TOO_LARGE] = ""
+		}
+		if baseConfig.Http.Timeout > 0 {
+			codes[httpx.STATUS_ERROR_TIMEOUT] = ""
+		}
+		for _, scheme := range route.security {
+			op.Security = append(op.Security, map[string][]string{scheme: {}})
+			securitySchemes[scheme] = securityScheme(scheme, baseConfig)
+			for _, code := range securityCodes[scheme] {
+				codes[code] = ""
+			}
+		}
+		for code, description := range route.codes {
+			codes[code] = description
+		}
+		format := route.format
+		if format == nil {
+			format = httpx.ResponseFromContext(context.Background())
+		}
+		// 默认错误时HTTP状态码也为200，使用真实HTTP状态码时错误按HTTP状态码分别列出，
+		// 响应格式实现了httpx.StatusResponse时始终使用真实HTTP状态码
+		_, statusResponse := format.(httpx.StatusResponse)
+		statusCodes := map[int]map[int]string{http.StatusOK: {}}
+		for code, description := range codes {
+			status := http.StatusOK
+			if (baseConfig.Http.HttpStatus || statusResponse) && code != httpx.STATUS_SUCCESS {
+				status = httpx.NewBizError(code, "").Status()
+			}
+			if statusCodes[status] == nil {
+				statusCodes[status] = make(map[int]string)
+			}
+			statusCodes[status][code] = description
+		}
+		op.Responses = make(map[string]*response, len(statusCodes))
+		for status, codes := range statusCodes {
+			op.Responses[strconv.Itoa(status)] = &response{
+				Description: codesDescription(codes),
+				Content:     responseContent(format, schemas.schema(route.resp), codes),
+			}
+		}
+
+		openAPIPath := openAPIPathPattern.ReplaceAllString(route.path, "{$1}")
+		if doc.Paths[openAPIPath] == nil {
+			doc.Paths[openAPIPath] = make(map[string]*operation)
+		}
+		doc.Paths[openAPIPath][strings.ToLower(route.method)] = op
+	}
+	doc.Components.Schemas = schemas.schemas
+	doc.Components.SecuritySchemes = securitySchemes
+	return doc
+}
+
+// 路径参数:id和*path转换为{id}和{path}
+var openAPIPathPattern = regexp.MustCompile(`[:*](\w+)`)
+
+func operationId(method, routePath string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.FieldsFunc(routePath, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+	return b.String()
+}
+
+func openAPIVersion(version string) string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "0.0.0"
+}
+
+func securityScheme(scheme string, baseConfig config.BaseConfig) any {
+	switch scheme {
+	case SECURITY_JWT:
+		return map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}
+	case SECURITY_OAUTH2:
+		return map[string]any{
+			"type": "oauth2",
+			"flows": map[string]any{
+				"clientCredentials": map[string]any{"tokenUrl": baseConfig.Http.OAuth2.TokenUri, "scopes": map[string]string{}},
+			},
+		}
+	case SECURITY_RPC:
+		return map[string]any{"type": "apiKey", "in": "header", "name": "inner_token_enc"}
+	}
+	return map[string]any{"type": "http", "scheme": "bearer"}
+}
+
+// codesDescription 在响应说明中列出可能的业务状态码
+func codesDescription(codes map[int]string) string {
+	lines := make([]string, 0, len(codes))
+	for _, code := range sortedCodes(codes) {
+		description := codes[code]
+		if description == "" {
+			info, _ := httpx.LookupCode(code)
+			description = info.Message
+		}
+		lines = append(lines, "- `"+strconv.Itoa(code)+"` "+description)
+	}
+	return "code:\n" + strings.Join(lines, "\n")
+}
+
+// sortedCodes 成功在前，其余按绝对值排序
+func sortedCodes(codes map[int]string) []int {
+	sorted := make([]int, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return abs(sorted[i]) < abs(sorted[j])
+	})
+	return sorted
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// responseContent 按响应格式生成一个HTTP状态码下的响应结构，codes为该状态码下的业务状态码
+func responseContent(format httpx.Response, data map[string]any, codes map[int]string) map[string]mediaType {
+	if _, ok := format.(*httpx.ProblemResponse); !ok {
+		if _, ok := codes[httpx.STATUS_SUCCESS]; !ok {
+			data = map[string]any{}
+		}
+		return map[string]mediaType{httpx.MIME_JSON: {Schema: envelopeSchema(data, codes)}}
+	}
+	// ProblemResponse成功时直接返回data，错误时返回application/problem+json
+	content := make(map[string]mediaType)
+	errorCodes := make(map[int]string, len(codes))
+	for code, description := range codes {
+		if code == httpx.STATUS_SUCCESS {
+			content[httpx.MIME_JSON] = mediaType{Schema: data}
+		} else {
+			errorCodes[code] = description
+		}
+	}
+	if len(errorCodes) > 0 {
+		content[httpx.MIME_PROBLEM_JSON] = mediaType{Schema: problemSchema(errorCodes)}
+	}
+	return content
+}
+
+// problemSchema httpx.ProblemResponse错误时的响应结构
+func problemSchema(codes map[int]string) map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"type", "title", "status", "code"},
+		"properties": map[string]any{
+			"type":    map[string]any{"type": "string"},
+			"title":   map[string]any{"type": "string"},
+			"status":  map[string]any{"type": "integer"},
+			"detail":  map[string]any{"type": "string"},
+			"code":    map[string]any{"type": "integer", "enum": sortedCodes(codes)},
+			"details": map[string]any{},
+		},
+	}
+}
+
+// envelopeSchema httpx.DefaultResponse的响应结构，即httpx.Ok和httpx.ErrorWithCode
+func envelopeSchema(data map[string]any, codes map[int]string) map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"code", "message", "data"},
+		"properties": map[string]any{
+			"code":    map[string]any{"type": "integer", "enum": sortedCodes(codes)},
+			"message": map[string]any{"type": "string"},
+			"data":    data,
+		},
+	}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	httpxTimeType = reflect.TypeOf(httpx.Time{})
+	httpxDateType = reflect.TypeOf(httpx.Date{})
+)
+
+// schemaBuilder 具名结构体放在components.schemas中以$ref引用
+type schemaBuilder struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+	types   map[string]reflect.Type
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]any),
+		names:   make(map[reflect.Type]string),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// request 路径参数(uri)、查询参数(form)和请求头(header)作为parameters，其余字段作为JSON请求体
+func (b *schemaBuilder) request(method string, t reflect.Type) ([]parameter, *requestBody) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	var params []parameter
+	body := map[string]any{}
+	var required []string
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldRequired := hasRule(field.Tag.Get("binding"), "required")
+			if name, ok := tagName(field, "uri"); ok {
+				params = append(params, parameter{Name: name, In: "path", Required: true, Schema: b.schema(field.Type)})
+				continue
+			}
+			if name, ok := tagName(field, "header"); ok {
+				params = append(params, parameter{Name: name, In: "header", Required: fieldRequired, Schema: b.schema(field.Type)})
+				continue
+			}
+			if name, ok := tagName(field, "form"); ok {
+				schema := b.schema(field.Type)
+				if _, options, _ := strings.Cut(field.Tag.Get("form"), ","); strings.HasPrefix(options, "default=") {
+					schema["default"] = strings.TrimPrefix(options, "default=")
+				}
+				params = append(params, parameter{Name: name, In: "query", Required: fieldRequired, Schema: schema})
+				continue
+			}
+			if field.Anonymous && field.Tag.Get("json") == "" && indirect(field.Type).Kind() == reflect.Struct {
+				walk(indirect(field.Type))
+				continue
+			}
+			name, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			body[name] = b.schema(field.Type)
+			if fieldRequired {
+				required = append(required, name)
+			}
+		}
+	}
+	walk(t)
+	if len(body) == 0 || method == http.MethodGet || method == http.MethodHead {
+		return params, nil
+	}
+	var schema map[string]any
+	if len(params) == 0 && t.Name() != "" {
+		schema = b.schema(t)
+	} else {
+		schema = map[string]any{"type": "object", "properties": body}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	return params, &requestBody{
+		Required: len(required) > 0,
+		Content:  map[string]mediaType{"application/json": {Schema: schema}},
+	}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	t = indirect(t)
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case httpxTimeType:
+		return map[string]any{"type": "string", "example": httpx.TIME_FORMAT}
+	case httpxDateType:
+		return map[string]any{"type": "string", "format": "date"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name, ok := b.names[t]
+		if !ok {
+			name = b.schemaName(t)
+			// 先占位，避免递归类型无限展开
+			b.schemas[name] = map[string]any{}
+			b.schemas[name] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Tag.Get("json") == "" && indirect(field.Type).Kind() == reflect.Struct {
+				walk(indirect(field.Type))
+				continue
+			}
+			name, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = b.schema(field.Type)
+			if hasRule(field.Tag.Get("binding"), "required") {
+				required = append(required, name)
+			}
+		}
+	}
+	walk(t)
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+var (
+	pkgPathPattern  = regexp.MustCompile(`[\w.\-]+/`)
+	nonIdentPattern = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// schemaName 泛型参数去掉包路径，如PageResult[example.com/app/model.User]为PageResult_model_User，重名时加包名前缀
+func (b *schemaBuilder) schemaName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i >= 0 {
+		params := pkgPathPattern.ReplaceAllString(name[i:], "")
+		name = name[:i] + "_" + strings.Trim(nonIdentPattern.ReplaceAllString(params, "_"), "_")
+	}
+	if other, ok := b.types[name]; ok && other != t {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	b.names[t] = name
+	b.types[name] = t
+	return name
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func tagName(field reflect.StructField, tag string) (string, bool) {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(value, ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	if name, ok := tagName(field, "json"); ok {
+		return name, true
+	}
+	return field.Name, field.Tag.Get("json") != "-"
+}
+
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// swaggerPage Swagger UI页面，静态资源从http.openapi.swaggerassets加载
+func swaggerPage(title, specPath, assets string) []byte {
+	url, _ := json.Marshal(specPath)
+	assets = html.EscapeString(strings.TrimSuffix(assets, "/"))
+	return []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <link rel="stylesheet" href="%s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%s/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: %s, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`, html.EscapeString(title), assets, assets, url))
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/middleware"
+)
+
+type testPage[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+	Next  *testPage[T]
+}
+
+func TestOpenAPI(t *testing.T) {
+	server := newTestServer(t)
+	findUser := func(ctx context.Context, req testUserReq) (testUserResp, error) {
+		return testUserResp{Id: req.Id}, nil
+	}
+	userGroup := server.Group("/api/user").Secure(SECURITY_JWT, middleware.JwtAuth(nil))
+	POST(userGroup, "/:id", findUser, Summary("find user"), ErrorCode(-404, "user not found"))
+	GET(server.Group(""), "/api/users", func(ctx context.Context, req struct {
+		Page int `form:"page,default=1"`
+	}) (testPage[testUserResp], error) {
+		return testPage[testUserResp]{}, nil
+	})
+	POST(server.RpcGroup(), "/user", findUser)
+	openGroup := server.Group("/open").ResponseFormat(&httpx.ProblemResponse{}).Secure(SECURITY_JWT, middleware.JwtAuth(nil))
+	GET(openGroup.Group("/user"), "/:id", findUser)
+	// 其他Server的路由不出现在文档中
+	GET(newTestServer(t).Group(""), "/other", findUser)
+
+	w := httptest.NewRecorder()
+	server.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", w.Code)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"openapi", `"3.0.3"`},
+		{"info.title", `"test"`},
+		{"paths./api/user/{id}.post.summary", `"find user"`},
+		{"paths./api/user/{id}.post.operationId", `"postApiUserId"`},
+		{"paths./api/user/{id}.post.tags", `["api"]`},
+		{"paths./api/user/{id}.post.security", `[{"jwt":[]}]`},
+		{"paths./api/user/{id}.post.parameters", `[{"in":"path","name":"id","required":true,"schema":{"format":"int64","type":"integer"}},` +
+			`{"in":"query","name":"fields","schema":{"items":{"type":"string"},"type":"array"}},` +
+			`{"in":"header","name":"X-Token","required":true,"schema":{"type":"string"}}]`},
+		{"paths./api/user/{id}.post.requestBody.content.application/json.schema.required", `["name"]`},
+		{"paths./api/user/{id}.post.responses.200.content.application/json.schema.properties.code.enum", `[0,-1,-404,-896,-999]`},
+		{"paths./api/user/{id}.post.responses.200.content.application/json.schema.properties.data", `{"$ref":"#/components/schemas/testUserResp"}`},
+		{"paths./api/users.get.parameters", `[{"in":"query","name":"page","schema":{"default":"1","format":"int64","type":"integer"}}]`},
+		{"paths./api/users.get.security", `null`},
+		{"paths./api/users.get.responses.200.content.application/json.schema.properties.data", `{"$ref":"#/components/schemas/testPage_rest_testUserResp"}`},
+		{"components.schemas.testPage_rest_testUserResp.properties.Next", `{"$ref":"#/components/schemas/testPage_rest_testUserResp"}`},
+		{"paths./_rpc_/user.post.security", `[{"rpc":[]}]`},
+		{"components.securitySchemes.jwt", `{"bearerFormat":"JWT","scheme":"bearer","type":"http"}`},
+		{"components.securitySchemes.rpc", `{"in":"header","name":"inner_token_enc","type":"apiKey"}`},
+		{"paths./open/user/{id}.get.security", `[{"jwt":[]}]`},
+		{"paths./open/user/{id}.get.responses.200.content", `{"application/json":{"schema":{"$ref":"#/components/schemas/testUserResp"}}}`},
+		{"paths./open/user/{id}.get.responses.401.content.application/problem+json.schema.properties.code", `{"enum":[-999],"type":"integer"}`},
+		{"paths./open/user/{id}.get.responses.400.content.application/problem+json.schema.required", `["type","title","status","code"]`},
+		{"paths./other", `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var v any = doc
+			for _, key := range strings.Split(tt.path, ".") {
+				m, _ := v.(map[string]any)
+				v = m[key]
+			}
+			got, _ := json.Marshal(v)
+			if string(got) != tt.want {
+				t.Errorf("%s = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	// 非debug时不提供Swagger UI
+	w = httptest.NewRecorder()
+	server.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /swagger = %d, want 404", w.Code)
+	}
+}
+
+func TestSwaggerPage(t *testing.T) {
+	page := string(swaggerPage("test", "/openapi.json", "/static/swagger/"))
+	for _, want := range []string{`href="/static/swagger/swagger-ui.css"`, `src="/static/swagger/swagger-ui-bundle.js"`, `url: "/openapi.json"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("swagger page missing %s", want)
+		}
+	}
+}
@@ -2,13 +2,17 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,36 +20,100 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/kappere/go-rest/core/config"
 	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/httpx"
 	"github.com/kappere/go-rest/core/logger"
+	"github.com/kappere/go-rest/core/metric"
 	"github.com/kappere/go-rest/core/middleware"
+	"github.com/kappere/go-rest/core/recovery"
 	"github.com/kappere/go-rest/core/rpc"
+	"github.com/kappere/go-rest/core/tool/load"
+	"github.com/kappere/go-rest/core/trace"
 )
 
+var log = logger.Module(logger.MODULE_REST)
+
+// GinEngine 最后创建的Server的Engine
+//
+// Deprecated: 使用Server.Engine，进程内有多个Server时该变量无法区分
 var GinEngine *gin.Engine
-var startTime time.Time
+
+// 日志和链路追踪导出器为进程级，进程内有多个Server时只由第一个创建的Server初始化
+var (
+	loggerOnce   sync.Once
+	traceOnce    sync.Once
+	reporterOnce sync.Once
+)
 
 type Server struct {
-	Engine         *gin.Engine
-	Config         config.BaseConfig
-	closeFunctions []func()
+	Engine     *gin.Engine
+	Config     config.BaseConfig
+	components lifecycle
+	health     health
+	startTime  time.Time
+
+	// 启动后有效
+	lock        sync.Mutex
+	httpServer  *http.Server
+	listener    net.Listener
+	serveErr    chan error
+	stopMonitor context.CancelFunc
+	// 可热更新的组件
+	accessLog         *middleware.AccessLogOptions
+	periodLimitUpdate func(conf.PeriodLimitConfig)
+	// 降载统计，未开启降载时为nil
+	sheddingStat *load.SheddingStat
+	// 管理端口，未开启时为nil
+	admin *adminServer
+	// 本服务的RPC客户端和panic上报器，不与进程内其他Server共享
+	rpcClient *rpc.Client
+	reporters []recovery.PanicReporter
+	// 通过Route注册的路由，用于生成本服务的OpenAPI文档
+	apiRoutesLock sync.Mutex
+	apiRoutes     map[string]*apiRoute
 }
 
+// NewServer 创建服务。http.httpstatus、panic上报webhook和RPC客户端为各Server独立的配置（参见setupRecovery）；
+// 日志和链路追踪为进程级，只由第一个创建的Server初始化，rpc.Service使用第一个创建的Server的RPC客户端
 func NewServer(baseConfig config.BaseConfig) *Server {
-	startTime = time.Now()
-	logger.InitLogger(baseConfig.Log, baseConfig.App.Name)
+	// 启动任何组件前校验配置
+	if err := baseConfig.Validate(); err != nil {
+		panic(fmt.Sprintf("Invalid config:\n%v", err))
+	}
+	startTime := time.Now()
+	loggerOnce.Do(func() {
+		logger.InitLogger(baseConfig.Log, baseConfig.App.Name)
+	})
 	// 启动服务组件
 	setupComponent(baseConfig)
 	// 创建engine
 	engine := createEngine(baseConfig)
-	GinEngine = engine
 	server := &Server{
-		Engine: engine,
-		Config: baseConfig,
+		Engine:    engine,
+		Config:    baseConfig,
+		startTime: startTime,
+		apiRoutes: make(map[string]*apiRoute),
+	}
+	GinEngine = engine
+	// 健康检查和指标，在中间件之前注册，不记录访问日志、不受限流影响
+	healthRouter(engine, &server.health)
+	if baseConfig.Http.Metrics.Enable {
+		engine.GET(baseConfig.Http.Metrics.Path, gin.WrapH(metric.Handler()))
 	}
+	// 接口文档
+	setupOpenAPI(server, baseConfig)
+	// 链路追踪导出
+	setupTrace(server, baseConfig)
+	// panic上报
+	setupRecovery(server, baseConfig)
 	// 初始化中间件
 	setupMiddleware(server, baseConfig)
+	// 管理端口
+	if baseConfig.Http.Admin.Enable {
+		server.admin = newAdminServer(baseConfig.Http.Admin, baseConfig.Http.Rpc.Token)
+	}
 	// 初始化RPC客户端
-	rpc.InitClient(baseConfig.Http.Rpc)
+	server.rpcClient = rpc.NewClient(baseConfig.Http.Rpc)
+	rpc.SetDefaultClient(server.rpcClient)
 	// 静态资源路由
 	staticResourceRouter(engine, baseConfig.Http)
 	// 初始化路由
@@ -53,19 +121,200 @@ func NewServer(baseConfig config.BaseConfig) *Server {
 	return server
 }
 
+// Run 启动服务并阻塞，收到SIGINT/SIGTERM后等待http.shutdowndelay，再在http.shutdowntimeout内优雅关闭
 func (s *Server) Run() {
-	// 启动服务
-	startHttpServer(s.Engine, s.Config.Http, startTime)
+	if err := s.Start(context.Background()); err != nil {
+		log.Error("Start server failed:", "error", err)
+		os.Exit(1)
+	}
+
+	// 等待中断信号以优雅地关闭服务器
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-quit:
+	case err := <-s.serveErr:
+		log.Error("Listen and serve failed:", "error", err)
+		s.Close()
+		os.Exit(1)
+	}
+	log.Info("Shutdown Server...")
+
+	// 摘除流量的等待不占用关闭超时
+	delay := time.Duration(s.Config.Http.ShutdownDelay) * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), delay+s.shutdownTimeout())
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		log.Error("Server force stop:", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Server closed.")
 }
 
+// Start 按依赖顺序启动组件，然后监听端口并在后台处理请求，不阻塞。
+// 端口为0时随机分配，可通过Addr获取实际地址。ctx用于组件启动和监听阶段。
+func (s *Server) Start(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.httpServer != nil {
+		return errors.New("server already started")
+	}
+	if err := s.components.start(ctx); err != nil {
+		return err
+	}
+	listener, err := listen(ctx, s.Config.Http)
+	if err != nil {
+		return errors.Join(err, s.components.stop(context.Background()))
+	}
+	if s.admin != nil {
+		if err := s.admin.start(ctx, s.Config.Http.Admin); err != nil {
+			listener.Close()
+			return errors.Join(fmt.Errorf("admin server: %w", err), s.components.stop(context.Background()))
+		}
+	}
+	srv := &http.Server{
+		Handler: s.Engine,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		// 服务连接
+		if err := serve(srv, listener, s.Config.Http); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+	}()
+	// 监控服务信息
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	setupMonitor(monitorCtx, s.sheddingStat)
+
+	s.httpServer = srv
+	s.listener = listener
+	s.serveErr = serveErr
+	s.stopMonitor = stopMonitor
+	s.health.setReady(true)
+	log.Info(fmt.Sprintf("Started server [%s] in %.3f seconds", listener.Addr(), float32(time.Now().UnixNano()-s.startTime.UnixNano())/1e9))
+	return nil
+}
+
+// Shutdown 优雅关闭：/readyz立即返回503，等待http.shutdowndelay让负载均衡摘除流量，
+// 再停止接收新请求，等待处理中的请求结束，然后逆序停止组件，均受ctx限制。返回所有错误的聚合。
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.setReady(false)
+	s.lock.Lock()
+	srv := s.httpServer
+	stopMonitor := s.stopMonitor
+	s.lock.Unlock()
+	var errs []error
+	if srv != nil {
+		if s.Config.Http.ShutdownDelay > 0 {
+			select {
+			case <-time.After(time.Duration(s.Config.Http.ShutdownDelay) * time.Millisecond):
+			case <-ctx.Done():
+			}
+		}
+		errs = append(errs, srv.Shutdown(ctx))
+		stopMonitor()
+	}
+	if s.admin != nil {
+		errs = append(errs, s.admin.shutdown(ctx))
+	}
+	errs = append(errs, s.components.stop(ctx))
+	return errors.Join(errs...)
+}
+
+// Addr 实际监听地址，未启动时为空
+func (s *Server) Addr() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// RpcClient 本服务的RPC客户端，随WatchConfig热更新
+func (s *Server) RpcClient() *rpc.Client {
+	return s.rpcClient
+}
+
+// AdminAddr 管理端口实际监听地址，未开启或未启动时为空
+func (s *Server) AdminAddr() string {
+	if s.admin == nil {
+		return ""
+	}
+	return s.admin.addr()
+}
+
+// Close 逆序停止已启动的组件和AddClose注册的关闭函数，多次调用只执行一次，最后刷新异步日志
 func (s *Server) Close() {
-	for i := range s.closeFunctions {
-		s.closeFunctions[len(s.closeFunctions)-1-i]()
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
+	defer cancel()
+	s.components.stop(ctx)
+	logger.Flush()
+}
+
+// Register 注册组件，需在Start前调用。实现了HealthChecker的组件同时加入就绪检查。
+func (s *Server) Register(components ...Component) {
+	s.components.register(components...)
+	for _, component := range components {
+		if checker, ok := component.(HealthChecker); ok {
+			s.health.add(checker)
+		}
 	}
 }
 
+// AddHealthChecker 注册就绪检查项，任一检查失败时/readyz返回503
+func (s *Server) AddHealthChecker(checkers ...HealthChecker) {
+	s.health.add(checkers...)
+}
+
+// AddClose 注册关闭函数，与已启动的组件一起逆序执行
 func (s *Server) AddClose(f func()) {
-	s.closeFunctions = append(s.closeFunctions, f)
+	s.components.addStarted(&FuncComponent{
+		ComponentName: "close",
+		StopFunc: func(ctx context.Context) error {
+			f()
+			return nil
+		},
+	})
+}
+
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.Config.Http.ShutdownTimeout > 0 {
+		return time.Duration(s.Config.Http.ShutdownTimeout) * time.Millisecond
+	}
+	return 8 * time.Second
+}
+
+// WatchConfig 订阅配置变更，热更新限流配额、RPC配置、全局和模块日志级别、TraceIgnorePaths和访问日志规则
+//
+//	watcher, err := config.NewWatcher(*configFile, newConfig)
+//	server.WatchConfig(watcher)
+//	watcher.Start()
+func (s *Server) WatchConfig(subscriber config.BaseConfigSubscriber) {
+	subscriber.SubscribeBase(func(old, new *config.BaseConfig) {
+		if s.periodLimitUpdate != nil && old.Http.PeriodLimit != new.Http.PeriodLimit {
+			s.periodLimitUpdate(new.Http.PeriodLimit)
+		}
+		if !reflect.DeepEqual(old.Http.Rpc, new.Http.Rpc) {
+			s.rpcClient.Update(new.Http.Rpc)
+			if s.admin != nil {
+				s.admin.setToken(new.Http.Rpc.Token)
+			}
+		}
+		if old.Log.Level != new.Log.Level {
+			if err := logger.SetLevel(new.Log.Level); err != nil {
+				log.Error("Invalid log level.", "level", new.Log.Level)
+			}
+		}
+		if !reflect.DeepEqual(old.Log.Modules, new.Log.Modules) {
+			if err := logger.SetModuleLevels(new.Log.Modules); err != nil {
+				log.Error("Invalid module log level.", "error", err)
+			}
+		}
+		if !reflect.DeepEqual(old.Http.TraceIgnorePaths, new.Http.TraceIgnorePaths) || !reflect.DeepEqual(old.Http.AccessLog, new.Http.AccessLog) {
+			s.accessLog.Set(new.Http.AccessLog, new.Http.TraceIgnorePaths...)
+		}
+	})
 }
 
 func createEngine(conf config.BaseConfig) *gin.Engine {
@@ -75,81 +324,209 @@ func createEngine(conf config.BaseConfig) *gin.Engine {
 	return gin.New()
 }
 
-func startHttpServer(engine *gin.Engine, httpConfig conf.HttpConfig, startTime time.Time) {
-	srv := &http.Server{
-		Addr:    ":" + strconv.Itoa(httpConfig.Port),
-		Handler: engine,
+// listen 监听端口，MaxConns大于0时限制并发连接数
+func listen(ctx context.Context, httpConfig conf.HttpConfig) (net.Listener, error) {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", ":"+strconv.Itoa(httpConfig.Port))
+	if err != nil {
+		return nil, err
 	}
-	go func() {
-		// 服务连接
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("Listen and serve failed:", "error", err)
-			os.Exit(1)
-		}
-	}()
-	// 监控服务信息
-	setupMonitor()
-	slog.Info(fmt.Sprintf("Started server [:%d] in %.3f seconds", httpConfig.Port, float32(time.Now().UnixNano()-startTime.UnixNano())/1e9))
-
-	// 等待中断信号以优雅地关闭服务器（设置 8 秒的超时时间）
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
-	slog.Info("Shutdown Server...")
+	if httpConfig.MaxConns > 0 {
+		listener = newLimitListener(listener, httpConfig.MaxConns)
+	}
+	return listener, nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		slog.Error("Server force stop:", "error", err)
-		os.Exit(1)
+// serve 配置了证书时使用https
+func serve(srv *http.Server, listener net.Listener, httpConfig conf.HttpConfig) error {
+	if httpConfig.CertFile != "" && httpConfig.KeyFile != "" {
+		return srv.ServeTLS(listener, httpConfig.CertFile, httpConfig.KeyFile)
 	}
-	slog.Info("Server closed.")
+	return srv.Serve(listener)
 }
 
 // 初始化服务组件
 func setupComponent(baseConfig config.BaseConfig) {
-	slog.Info("================================")
-	slog.Info("app     : " + baseConfig.App.Name)
-	slog.Info("profile : " + baseConfig.App.Profile)
-	slog.Info("debug   : " + strconv.FormatBool(baseConfig.App.Debug))
-	slog.Info("runtime : " + runtime.GOOS + "-" + runtime.GOARCH)
-	slog.Info("exec    : " + os.Args[0])
-	slog.Info("logdir  : " + baseConfig.Log.Path)
-	slog.Info("port    : " + strconv.Itoa(baseConfig.Http.Port))
-	slog.Info("================================")
+	log.Info("================================")
+	log.Info("app     : " + baseConfig.App.Name)
+	log.Info("profile : " + baseConfig.App.Profile)
+	log.Info("debug   : " + strconv.FormatBool(baseConfig.App.Debug))
+	log.Info("runtime : " + runtime.GOOS + "-" + runtime.GOARCH)
+	log.Info("exec    : " + os.Args[0])
+	log.Info("logdir  : " + baseConfig.Log.Path)
+	log.Info("port    : " + strconv.Itoa(baseConfig.Http.Port))
+	log.Info("tls     : " + strconv.FormatBool(baseConfig.Http.CertFile != ""))
+	if baseConfig.Http.Admin.Enable {
+		log.Info("admin   : " + net.JoinHostPort(baseConfig.Http.Admin.Host, strconv.Itoa(baseConfig.Http.Admin.Port)))
+	}
+	log.Info("================================")
 }
 
 // 初始化中间件
 func setupMiddleware(server *Server, baseConfig config.BaseConfig) {
+	// 服务级配置放入请求context，进程内多个Server互不影响
+	server.Engine.Use(serverContext(server, baseConfig))
+
+	// 请求指标，在最外层以统计恢复后的500
+	if baseConfig.Http.Metrics.Enable {
+		server.Engine.Use(middleware.Metrics())
+		log.Info("[middleware] Metrics")
+	}
+
+	// 链路追踪，在恢复中间件外层以记录500
+	server.Engine.Use(middleware.Trace())
+	log.Info("[middleware] Trace")
+
 	// 错误恢复中间件
-	server.Engine.Use(middleware.NiceRecovery())
-	slog.Info("[middleware] NiceRecovery")
+	server.Engine.Use(middleware.NiceRecoveryWithConfig(baseConfig.Http.Recovery))
+	log.Info("[middleware] NiceRecovery")
 
 	// 自定义日志格式
-	server.Engine.Use(middleware.NiceLoggerFormatter(nil, baseConfig.App.Debug))
-	slog.Info("[middleware] NiceLoggerFormatter")
+	server.accessLog = middleware.NewAccessLogOptions(baseConfig.Http.AccessLog, baseConfig.Http.TraceIgnorePaths...)
+	server.Engine.Use(middleware.NiceLoggerWithOptions(nil, baseConfig.App.Debug, server.accessLog))
+	log.Info("[middleware] NiceLoggerFormatter")
 
 	// 请求ID
-	server.Engine.Use(requestid.New())
-	slog.Info("[middleware] requestid")
+	server.Engine.Use(requestid.New(requestid.WithHandler(func(c *gin.Context, requestID string) {
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+	})))
+	log.Info("[middleware] requestid")
+
+	// 请求体大小限制
+	if baseConfig.Http.MaxBytes > 0 {
+		server.Engine.Use(middleware.MaxBytes(baseConfig.Http.MaxBytes))
+		log.Info("[middleware] MaxBytes (" + strconv.FormatInt(baseConfig.Http.MaxBytes, 10) + ")")
+	}
+
+	// 请求超时
+	if baseConfig.Http.Timeout > 0 {
+		server.Engine.Use(middleware.Timeout(time.Duration(baseConfig.Http.Timeout) * time.Millisecond))
+		log.Info("[middleware] Timeout (" + strconv.FormatInt(baseConfig.Http.Timeout, 10) + "ms)")
+	}
+
+	// 自适应降载
+	if baseConfig.Http.CpuThreshold > 0 {
+		shedder := load.NewAdaptiveShedder(baseConfig.Http.CpuThreshold)
+		server.Engine.Use(middleware.Shedding(shedder))
+		server.sheddingStat = shedder.Stat()
+		log.Info("[middleware] Shedding (cpu threshold " + strconv.FormatInt(baseConfig.Http.CpuThreshold, 10) + ")")
+	}
 
 	// Session
-	if baseConfig.Http.Session.StoreType != "" {
+	if baseConfig.Http.Session.StoreType != "" && baseConfig.Http.Session.StoreType != middleware.STORAGE_TYPE_NONE {
 		server.Engine.Use(middleware.Session(baseConfig.Http.Session, baseConfig.Redis))
-		slog.Info("[middleware] Session (" + baseConfig.Http.Session.StoreType + ")")
+		log.Info("[middleware] Session (" + baseConfig.Http.Session.StoreType + ")")
 	}
 
 	// 限流
 	if baseConfig.Http.PeriodLimit.Enable {
 		if baseConfig.Http.PeriodLimit.Distributed {
-			limit, close := middleware.PeriodLimitDistributedMiddleware(baseConfig.Http.PeriodLimit, baseConfig.Redis)
-			server.Engine.Use(limit)
-			server.AddClose(close)
+			limit := middleware.NewPeriodLimitDistributed(baseConfig.Http.PeriodLimit, baseConfig.Redis)
+			server.Engine.Use(limit.Handler())
+			server.AddClose(limit.Close)
+			server.periodLimitUpdate = limit.Update
 		} else {
-			limit := middleware.PeriodLimitLocalMiddleware(baseConfig.Http.PeriodLimit)
-			server.Engine.Use(limit)
+			limit := middleware.NewPeriodLimitLocal(baseConfig.Http.PeriodLimit)
+			server.Engine.Use(limit.Handler())
+			server.periodLimitUpdate = limit.Update
+		}
+		log.Info("[middleware] PeriodLimitMiddleware")
+	}
+}
+
+// serverContext 为请求设置本服务的http.httpstatus和panic上报器
+func serverContext(server *Server, baseConfig config.BaseConfig) gin.HandlerFunc {
+	httpStatus := baseConfig.Http.HttpStatus
+	return func(c *gin.Context) {
+		ctx := httpx.WithHttpStatusMode(c.Request.Context(), httpStatus)
+		if len(server.reporters) > 0 {
+			ctx = recovery.WithReporters(ctx, server.reporters...)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// setupTrace 配置导出器，未开启时只传播traceparent不导出span。
+// 导出器作为最先注册的组件，在其他组件停止后才关闭，保证span全部导出。
+// 导出器为进程级，已由其他Server初始化时忽略本服务的配置。
+func setupTrace(server *Server, baseConfig config.BaseConfig) {
+	traceConfig := baseConfig.Trace
+	if !traceConfig.Enable {
+		return
+	}
+	first := false
+	traceOnce.Do(func() { first = true })
+	if !first {
+		log.Warn("[trace] exporter already initialized by another server, ignore trace config")
+		return
+	}
+	var exporter trace.Exporter
+	switch strings.ToLower(traceConfig.Exporter) {
+	case "otlp":
+		exporter = trace.NewOtlpExporter(traceConfig.Endpoint, traceConfig.Headers...)
+	case "file":
+		fileExporter, err := trace.NewFileExporter(traceConfig.File)
+		if err != nil {
+			panic(err)
+		}
+		exporter = fileExporter
+	default:
+		exporter = trace.NewWriterExporter(os.Stdout)
+	}
+	trace.SetServiceName(baseConfig.App.Name)
+	trace.SetSampleRatio(traceConfig.SampleRatio)
+	trace.SetExporter(exporter)
+	server.Register(&FuncComponent{
+		ComponentName: "trace",
+		StopFunc:      trace.Shutdown,
+	})
+	log.Info("[trace] exporter " + traceConfig.Exporter)
+}
+
+// 初始化panic上报，关闭时等待进行中的上报完成。
+// 第一个配置了webhook的Server注册为全局上报器，同时接收定时任务和后台goroutine的panic；
+// 其余Server的webhook只接收本服务请求中的panic，避免重复注册
+func setupRecovery(server *Server, baseConfig config.BaseConfig) {
+	recoveryConfig := baseConfig.Http.Recovery
+	if recoveryConfig.Webhook != "" {
+		reporter := recovery.NewWebhookReporter(recoveryConfig.Webhook, baseConfig.App.Name, recoveryConfig.WebhookHeaders...)
+		global := false
+		reporterOnce.Do(func() {
+			recovery.AddReporter(reporter)
+			global = true
+		})
+		if !global {
+			server.reporters = append(server.reporters, reporter)
+		}
+		log.Info("[recovery] webhook reporter")
+	}
+	server.Register(&FuncComponent{
+		ComponentName: "recovery",
+		StopFunc:      recovery.Flush,
+	})
+}
+
+// 初始化OpenAPI文档，Swagger UI仅在debug时提供
+func setupOpenAPI(server *Server, baseConfig config.BaseConfig) {
+	openApiConfig := baseConfig.Http.OpenApi
+	if !openApiConfig.Enable {
+		return
+	}
+	server.Engine.GET(openApiConfig.Path, func(c *gin.Context) {
+		data, err := server.OpenAPI()
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
 		}
-		slog.Info("[middleware] PeriodLimitMiddleware")
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	})
+	if baseConfig.App.Debug {
+		page := swaggerPage(baseConfig.App.Name, openApiConfig.Path, openApiConfig.SwaggerAssets)
+		server.Engine.GET(openApiConfig.SwaggerPath, func(c *gin.Context) {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+		})
+		log.Info("[openapi] swagger ui " + openApiConfig.SwaggerPath)
 	}
 }
 
@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kappere/go-rest/core/config"
+	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+func newTestServer(t *testing.T, options ...func(c *conf.HttpConfig)) *Server {
+	t.Helper()
+	c := config.DefaultBaseConfig
+	c.App.Name = "test"
+	c.Log.Path = t.TempDir()
+	c.Http.Port = 0
+	c.Http.ShutdownDelay = 0
+	c.Http.Rpc.IpProxy = conf.IpProxyConfig{}
+	c.Http.Rpc.Kubernetes = conf.KubernetesConfig{}
+	for _, option := range options {
+		option(&c.Http)
+	}
+	return NewServer(c)
+}
+
+func TestServerStartShutdown(t *testing.T) {
+	server := newTestServer(t)
+	server.Engine.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	closed := false
+	server.AddClose(func() { closed = true })
+
+	if server.Addr() != "" {
+		t.Errorf("Addr() before Start = %q, want empty", server.Addr())
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Start(context.Background()); err == nil {
+		t.Error("expect error when starting twice")
+	}
+
+	resp, err := http.Get("http://" + server.Addr() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "pong" {
+		t.Errorf("GET /ping = %d %s", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get("http://" + server.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if want := `http_requests_total{method="GET",route="/ping",status="200"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("metrics missing %q", want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !closed {
+		t.Error("close hook not called on Shutdown")
+	}
+	if _, err := http.Get("http://" + server.Addr() + "/ping"); err == nil {
+		t.Error("expect request failed after Shutdown")
+	}
+}
+
+func TestServerHealth(t *testing.T) {
+	server := newTestServer(t)
+	server.Config.Http.ShutdownDelay = 500
+	var redisErr atomic.Value
+	redisErr.Store("")
+	server.AddHealthChecker(HealthCheckerFunc{
+		CheckerName: "redis",
+		CheckFunc: func(ctx context.Context) error {
+			if msg := redisErr.Load().(string); msg != "" {
+				return errors.New(msg)
+			}
+			return nil
+		},
+	})
+	get := func(path string) (int, healthResult) {
+		t.Helper()
+		resp, err := http.Get("http://" + server.Addr() + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var result healthResult
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get(HEALTHZ_PATH); code != http.StatusOK {
+		t.Errorf("healthz = %d", code)
+	}
+	if code, result := get(READYZ_PATH); code != http.StatusOK || result.Checks["redis"] != "ok" {
+		t.Errorf("readyz = %d %+v", code, result)
+	}
+
+	// 检查项失败
+	redisErr.Store("connection refused")
+	if code, result := get(READYZ_PATH); code != http.StatusServiceUnavailable || result.Checks["redis"] != "connection refused" {
+		t.Errorf("readyz with failed checker = %d %+v", code, result)
+	}
+	redisErr.Store("")
+
+	// 开始关闭后立即不可用，存活检查仍可用
+	done := make(chan error)
+	go func() {
+		done <- server.Shutdown(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if code, result := get(READYZ_PATH); code != http.StatusServiceUnavailable || result.Status != "unavailable" {
+		t.Errorf("readyz during shutdown = %d %+v", code, result)
+	}
+	if code, _ := get(HEALTHZ_PATH); code != http.StatusOK {
+		t.Errorf("healthz during shutdown = %d", code)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndependentServers(t *testing.T) {
+	a := newTestServer(t, func(c *conf.HttpConfig) {
+		c.HttpStatus = true
+		c.Rpc.IpProxy.Proxy = map[string]string{"*": "http://a:8080"}
+	})
+	b := newTestServer(t, func(c *conf.HttpConfig) {
+		c.Rpc.IpProxy.Proxy = map[string]string{"*": "http://b:8080"}
+	})
+	for _, s := range []*Server{a, b} {
+		s.Engine.GET("/fail", Handle(func(ctx context.Context, req struct{}) (any, error) {
+			return nil, httpx.ErrParam
+		}))
+	}
+	tests := []struct {
+		name   string
+		server *Server
+		status int
+		addr   string
+	}{
+		{"http status enabled", a, http.StatusBadRequest, "http://a:8080"},
+		{"http status disabled", b, http.StatusOK, "http://b:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.server.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if addr := tt.server.RpcClient().Service("user").Addr; addr != tt.addr {
+				t.Errorf("rpc addr = %s, want %s", addr, tt.addr)
+			}
+		})
+	}
+	if GinEngine != b.Engine {
+		t.Error("GinEngine should be the last created engine")
+	}
+}
@@ -2,7 +2,6 @@ package rest
 
 import (
 	"embed"
-	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -37,7 +36,7 @@ func staticResourceHandler(engine *gin.Engine, staticResourceConfig conf.StaticR
 			}
 			fileServer.ServeHTTP(c.Writer, r2)
 		})
-		slog.Info("Static resource mapping: [NoRoute] => embed" + fsPrefix)
+		log.Info("Static resource mapping: [NoRoute] => embed" + fsPrefix)
 	} else if httpDirFs, ok := staticFs.(http.Dir); ok {
 		fileServer := http.FileServer(httpDirFs)
 		engine.GET("/", func(c *gin.Context) {
@@ -52,6 +51,6 @@ func staticResourceHandler(engine *gin.Engine, staticResourceConfig conf.StaticR
 		engine.NoRoute(func(c *gin.Context) {
 			fileServer.ServeHTTP(c.Writer, c.Request)
 		})
-		slog.Info("static resource mapping: [NoRoute] => " + string(httpDirFs))
+		log.Info("static resource mapping: [NoRoute] => " + string(httpDirFs))
 	}
 }
@@ -1,47 +1,80 @@
 package rpc
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
-	"log/slog"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kappere/go-rest/core/config/conf"
 	"github.com/kappere/go-rest/core/httpx"
+	"github.com/kappere/go-rest/core/logger"
+	"github.com/kappere/go-rest/core/metric"
+	"github.com/kappere/go-rest/core/trace"
 )
 
-var rpcConf conf.RpcConfig
+var log = logger.Module(logger.MODULE_RPC)
 
-var srvLookup func(srvname string) RpcService
+var (
+	rpcDuration = metric.NewHistogramVec("rpc_client_duration_seconds",
+		"RPC client call latency by service.", nil, "service")
+	// reason: transport/status/code，分别为请求失败、HTTP 5xx和非0业务状态码
+	rpcErrors = metric.NewCounterVec("rpc_client_errors_total",
+		"Number of failed RPC client calls by service and reason.", "service", "reason")
+)
+
+// 调用失败原因，rpc_client_errors_total的reason标签
+const (
+	ERROR_REASON_TRANSPORT = "transport"
+	ERROR_REASON_STATUS    = "status"
+	ERROR_REASON_CODE      = "code"
+)
+
+// Client RPC客户端，每个rest.Server持有一个，Service等包级函数使用默认客户端
+type Client struct {
+	// 保护conf和lookup，配置热更新时Update
+	lock   sync.RWMutex
+	conf   conf.RpcConfig
+	lookup func(srvname string) RpcService
+}
+
+// 包级函数使用的客户端，参见SetDefaultClient
+var defaultClient atomic.Pointer[Client]
 
 type RpcService struct {
 	Name string
 	Addr string
+	// 调用时计算inner_token_enc的token，取自所属Client的配置
+	token string
+	// 服务的响应格式，为nil时使用httpx.SetResponse设置的全局格式
+	format httpx.Response
 }
 
 type RpcResult struct {
-	Data []byte
-	Err  error
+	Data        []byte
+	Err         error
+	StatusCode  int
+	ContentType string
+	format      httpx.Response
 }
 
+// ToMap 按服务的响应格式解析响应（参见RpcService.WithResponse和httpx.DecodeResponse），业务错误时返回*httpx.BizError
 func (r RpcResult) ToMap() (interface{}, error) {
-	resp := httpx.Ok(nil)
-	err := json.Unmarshal(r.Data, &resp)
-	if err != nil {
-		return nil, err
-	}
-	if resp.Error() != nil {
-		return nil, resp.Error()
+	if r.Err != nil {
+		return nil, r.Err
 	}
-	return resp.GetData(), nil
+	return httpx.DecodeResponse(r.format, r.StatusCode, r.ContentType, r.Data)
 }
 
 func (r RpcResult) ToObj(result interface{}) error {
@@ -56,16 +89,39 @@ func (r RpcResult) ToObj(result interface{}) error {
 	return json.Unmarshal(objByteData, result)
 }
 
+// NewClient 创建RPC客户端
+func NewClient(c conf.RpcConfig) *Client {
+	client := &Client{}
+	client.Update(c)
+	return client
+}
+
+// InitClient 初始化默认客户端，可重复调用以更新配置
 func InitClient(c conf.RpcConfig) {
-	rpcConf = c
-	slog.Info("Init rpc client,", "type", rpcConf.Type)
-	if strings.ToLower(rpcConf.Type) == "kubernetes" {
+	if client := defaultClient.Load(); client != nil {
+		client.Update(c)
+		return
+	}
+	SetDefaultClient(NewClient(c))
+}
+
+// SetDefaultClient 设置包级函数Service使用的客户端，已设置时不覆盖并返回false。
+// 进程内有多个rest.Server时以第一个创建的为准，其余Server通过Server.RpcClient()调用
+func SetDefaultClient(client *Client) bool {
+	return defaultClient.CompareAndSwap(nil, client)
+}
+
+// Update 更新配置，用于配置热更新
+func (client *Client) Update(c conf.RpcConfig) {
+	var lookup func(srvname string) RpcService
+	log.Info("Init rpc client.", "type", c.Type)
+	if strings.ToLower(c.Type) == "kubernetes" {
 		if isInKubernetesCluster() {
-			slog.Info("In kubernetes")
+			log.Info("In kubernetes")
 			// minikube需要先添加service读取权限
 			// kubectl create clusterrolebinding service-reader-pod --clusterrole=service-reader --serviceaccount=default:default
-			srvLookup = func(srvname string) RpcService {
-				_, addrs, _ := net.LookupSRV(rpcConf.Kubernetes.PortName, "tcp", srvname)
+			lookup = func(srvname string) RpcService {
+				_, addrs, _ := net.LookupSRV(c.Kubernetes.PortName, "tcp", srvname)
 				if len(addrs) > 0 {
 					addr := "http://" + addrs[0].Target + ":" + strconv.FormatInt(int64(addrs[0].Port), 10)
 					return RpcService{
@@ -76,14 +132,14 @@ func InitClient(c conf.RpcConfig) {
 				return RpcService{Name: srvname}
 			}
 		} else {
-			slog.Info("Out of kubernetes")
-			defaultProxyAddr := rpcConf.Kubernetes.Proxy["*"]
-			srvLookup = func(srvname string) RpcService {
-				addr := rpcConf.Kubernetes.Proxy[srvname]
+			log.Info("Out of kubernetes")
+			defaultProxyAddr := c.Kubernetes.Proxy["*"]
+			lookup = func(srvname string) RpcService {
+				addr := c.Kubernetes.Proxy[srvname]
 				if addr == "" {
 					addr = defaultProxyAddr
 				}
-				addr = strings.ReplaceAll(addr, "{namespace}", rpcConf.Kubernetes.Namespace)
+				addr = strings.ReplaceAll(addr, "{namespace}", c.Kubernetes.Namespace)
 				addr = strings.ReplaceAll(addr, "{app}", srvname)
 				return RpcService{
 					Name: srvname,
@@ -91,10 +147,10 @@ func InitClient(c conf.RpcConfig) {
 				}
 			}
 		}
-	} else if strings.ToLower(rpcConf.Type) == "ipproxy" {
-		defaultProxyAddr := rpcConf.IpProxy.Proxy["*"]
-		srvLookup = func(srvname string) RpcService {
-			addr := rpcConf.IpProxy.Proxy[srvname]
+	} else if strings.ToLower(c.Type) == "ipproxy" {
+		defaultProxyAddr := c.IpProxy.Proxy["*"]
+		lookup = func(srvname string) RpcService {
+			addr := c.IpProxy.Proxy[srvname]
 			if addr == "" {
 				addr = defaultProxyAddr
 			}
@@ -104,55 +160,125 @@ func InitClient(c conf.RpcConfig) {
 			}
 		}
 	}
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	client.conf = c
+	client.lookup = lookup
+}
+
+// Service 查找服务，未找到时panic
+func (client *Client) Service(srvname string) RpcService {
+	client.lock.RLock()
+	lookup := client.lookup
+	token := client.conf.Token
+	client.lock.RUnlock()
+	if lookup == nil {
+		panic("Rpc client not initialized")
+	}
+	srv := lookup(srvname)
+	if srv.Addr == "" {
+		panic("Service not found: " + srvname)
+	}
+	srv.token = token
+	return srv
+}
+
+// WithResponse 指定服务的响应格式，服务与本服务的全局响应格式不同时使用，如httpx.ProblemResponse
+//
+//	rpc.Service("user").WithResponse(&httpx.ProblemResponse{}).CallContext(ctx, "/user/get?id=1", nil)
+func (service RpcService) WithResponse(format httpx.Response) RpcService {
+	service.format = format
+	return service
 }
 
 func (service RpcService) Call(url string, body map[string]interface{}) RpcResult {
-	data, err := httpPost(service.Addr+RPC_PREFIX+url, body)
-	if err != nil {
-		return RpcResult{nil, err}
+	return service.CallContext(context.Background(), url, body)
+}
+
+// CallContext 调用服务，ctx中的trace通过traceparent请求头传递给下游，
+// 在handler中调用时传入c.Request.Context()
+func (service RpcService) CallContext(ctx context.Context, url string, body map[string]interface{}) RpcResult {
+	ctx, span := trace.Start(ctx, "RPC "+service.Name+" "+url, trace.KIND_CLIENT)
+	span.SetAttr("rpc.service", service.Name)
+	span.SetAttr("http.url", service.Addr+RPC_PREFIX+url)
+	defer span.End()
+	start := time.Now()
+	result := httpPost(ctx, service.Addr+RPC_PREFIX+url, body, service.token)
+	result.format = service.format
+	latency := time.Since(start)
+	rpcDuration.Observe(latency.Seconds(), service.Name)
+	if result.Err != nil {
+		span.SetError(result.Err)
+		rpcErrors.Inc(service.Name, ERROR_REASON_TRANSPORT)
+		log.WarnContext(ctx, "Rpc call failed.", "service", service.Name, "url", url, "latency", latency, "error", result.Err)
+		return result
 	}
-	return RpcResult{data, nil}
+	if reason := result.errorReason(); reason != "" {
+		rpcErrors.Inc(service.Name, reason)
+	}
+	log.DebugContext(ctx, "Rpc call.", "service", service.Name, "url", url, "latency", latency, "status", result.StatusCode)
+	return result
 }
 
-func httpPost(url string, body map[string]interface{}) ([]byte, error) {
+// errorReason 响应为HTTP 5xx或业务错误时返回失败原因，成功时为空
+func (r RpcResult) errorReason() string {
+	if r.StatusCode >= http.StatusInternalServerError {
+		return ERROR_REASON_STATUS
+	}
+	var bizError *httpx.BizError
+	if _, err := r.ToMap(); errors.As(err, &bizError) {
+		return ERROR_REASON_CODE
+	}
+	return ""
+}
+
+func httpPost(ctx context.Context, url string, body map[string]interface{}, token string) RpcResult {
 	reqbody := strings.NewReader("")
 	if body != nil {
 		jsonbody, _ := json.Marshal(body)
 		reqbody = strings.NewReader(string(jsonbody))
 	}
-	request, err := http.NewRequest("POST", url, reqbody)
+	request, err := http.NewRequestWithContext(ctx, "POST", url, reqbody)
 	if err != nil {
-		return nil, err
+		return RpcResult{Err: err}
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
 	}
-	return apply(request)
+	if span := trace.FromContext(ctx); span != nil {
+		request.Header.Set(trace.TRACEPARENT_HEADER, span.SpanContext.Traceparent())
+	}
+	return apply(request, token)
 }
 
-func apply(request *http.Request) ([]byte, error) {
+func apply(request *http.Request, token string) RpcResult {
 	client := &http.Client{}
 
 	// 计算token
-	if rpcConf.Token != "" {
+	if token != "" {
 		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
 		hash := sha256.New()
 		randstr := strconv.Itoa(rand.Int())
-		hash.Write([]byte(rpcConf.Token + "#" + randstr + "#" + timestamp))
+		hash.Write([]byte(token + "#" + randstr + "#" + timestamp))
 		enc := hex.EncodeToString(hash.Sum(nil))
 		request.Header.Add("inner_token_enc", enc+"#"+randstr+"#"+timestamp)
 	}
 	response, err := client.Do(request)
 	if err != nil {
-		return nil, err
+		return RpcResult{Err: err}
 	}
 	defer response.Body.Close()
-	return io.ReadAll(response.Body)
+	data, err := io.ReadAll(response.Body)
+	return RpcResult{Data: data, Err: err, StatusCode: response.StatusCode, ContentType: response.Header.Get("Content-Type")}
 }
 
+// Service 使用默认客户端查找服务
 func Service(srvname string) RpcService {
-	srv := srvLookup(srvname)
-	if srv.Addr == "" {
-		panic("Service not found: " + srvname)
+	client := defaultClient.Load()
+	if client == nil {
+		panic("Rpc client not initialized")
 	}
-	return srv
+	return client.Service(srvname)
 }
 
 func isInKubernetesCluster() bool {
@@ -0,0 +1,102 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kappere/go-rest/core/config/conf"
+	"github.com/kappere/go-rest/core/httpx"
+)
+
+func TestCallErrorMetric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case RPC_PREFIX + "/ok":
+			w.Write([]byte(`{"code":0,"message":"","data":1}`))
+		case RPC_PREFIX + "/code":
+			w.Write([]byte(`{"code":-1001,"message":"user not found","data":null}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(conf.RpcConfig{Type: "IpProxy", IpProxy: conf.IpProxyConfig{Proxy: map[string]string{"*": server.URL}}})
+	down := NewClient(conf.RpcConfig{Type: "IpProxy", IpProxy: conf.IpProxyConfig{Proxy: map[string]string{"*": "http://127.0.0.1:1"}}})
+	tests := []struct {
+		name    string
+		service RpcService
+		url     string
+		reason  string
+	}{
+		{"success", client.Service("metric_ok"), "/ok", ""},
+		{"business code", client.Service("metric_code"), "/code", ERROR_REASON_CODE},
+		{"http 5xx", client.Service("metric_status"), "/status", ERROR_REASON_STATUS},
+		{"transport", down.Service("metric_transport"), "/ok", ERROR_REASON_TRANSPORT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.service.CallContext(context.Background(), tt.url, nil)
+			for _, reason := range []string{ERROR_REASON_TRANSPORT, ERROR_REASON_STATUS, ERROR_REASON_CODE} {
+				want := 0.0
+				if reason == tt.reason {
+					want = 1
+				}
+				if got := rpcErrors.Value(tt.service.Name, reason); got != want {
+					t.Errorf("rpc_client_errors_total{reason=%q} = %v, want %v", reason, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCallDecode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case RPC_PREFIX + "/problem":
+			w.Header().Set("Content-Type", httpx.MIME_PROBLEM_JSON)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"about:blank","title":"user not found","status":404,"detail":"user 7 not found","code":-1001}`))
+		case RPC_PREFIX + "/plain":
+			// ProblemResponse成功时直接返回data，data本身带有code和message字段
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"code":-1,"message":"domain message","id":7}`))
+		case RPC_PREFIX + "/envelope":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"code":-1001,"message":"user not found","data":null}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(conf.RpcConfig{Type: "IpProxy", IpProxy: conf.IpProxyConfig{Proxy: map[string]string{"*": server.URL}}})
+	problem := client.Service("user").WithResponse(&httpx.ProblemResponse{})
+	tests := []struct {
+		name     string
+		service  RpcService
+		url      string
+		wantData string
+		wantCode int
+	}{
+		{"problem error", problem, "/problem", "", -1001},
+		{"problem error with default format", client.Service("user"), "/problem", "", -1001},
+		{"plain body with code and message", problem, "/plain", "map[code:-1 id:7 message:domain message]", 0},
+		{"default envelope", client.Service("user"), "/envelope", "", -1001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.service.CallContext(context.Background(), tt.url, nil).ToMap()
+			if tt.wantCode == 0 {
+				if err != nil || fmt.Sprint(data) != tt.wantData {
+					t.Errorf("ToMap() = %v, %v, want %s", data, err, tt.wantData)
+				}
+				return
+			}
+			var bizError *httpx.BizError
+			if !errors.As(err, &bizError) || bizError.Code != tt.wantCode {
+				t.Errorf("ToMap() error = %#v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
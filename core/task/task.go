@@ -10,10 +10,14 @@
 package task
 
 import (
-	"log"
-	"runtime"
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
 
-	"github.com/google/uuid"
+	"github.com/kappere/go-rest/core/logger"
+	"github.com/kappere/go-rest/core/metric"
+	"github.com/kappere/go-rest/core/recovery"
 	"github.com/robfig/cron"
 )
 
@@ -23,43 +27,87 @@ type Task interface {
 
 var c = cron.New()
 
-var task_status = make(map[string]bool)
+var log = logger.Module(logger.MODULE_TASK)
+
+// 调度器是否在运行
+var running atomic.Bool
+
+var (
+	taskRuns     = metric.NewCounterVec("task_runs_total", "Number of task runs by task name.", "task")
+	taskFailures = metric.NewCounterVec("task_failures_total", "Number of panicked task runs by task name.", "task")
+	taskSkipped  = metric.NewCounterVec("task_skipped_total", "Number of task runs skipped because previous run was still running.", "task")
+	taskDuration = metric.NewHistogramVec("task_duration_seconds", "Task run duration by task name.",
+		[]float64{.1, .5, 1, 5, 10, 30, 60, 300, 600}, "task")
+)
 
 func NewTask(cron string, name string, t Task) {
 	NewTaskFunc(cron, name, t.Process)
 }
 
 func NewTaskFunc(cron string, name string, t func()) {
-	task_id := uuid.NewString()
-	task_status[task_id] = false
+	// 任务是否正在运行，由cron的各个goroutine并发访问
+	var taskRunning atomic.Bool
 	c.AddFunc(cron, func() {
-		if checkPreviousTaskStatus(task_id) {
-			log.Printf("==== Task [%s] previous version was running ====", name)
+		if !taskRunning.CompareAndSwap(false, true) {
+			log.Warn("Task skipped, previous run is still running.", "task", name)
+			taskSkipped.Inc(name)
 			return
 		}
-		task_status[task_id] = true
+		taskRuns.Inc(name)
+		start := time.Now()
 		defer func() {
-			task_status[task_id] = false
+			taskRunning.Store(false)
+			taskDuration.Observe(time.Since(start).Seconds(), name)
 			if r := recover(); r != nil {
-				log.Printf("==== Task [%s] failed ====", name)
-				const size = 64 << 10
-				buf := make([]byte, size)
-				buf = buf[:runtime.Stack(buf, false)]
-				log.Printf("cron: panic running job: %v\n%s", r, buf)
+				taskFailures.Inc(name)
+				log.Error("Task failed.", "task", name, "latency", time.Since(start), "error", r)
+				p := recovery.NewPanic(context.Background(), recovery.SOURCE_TASK, r)
+				p.Path = name
+				recovery.Report(context.Background(), p)
 			} else {
-				log.Printf("==== Task [%s] finished ====", name)
+				log.Info("Task finished.", "task", name, "latency", time.Since(start))
 			}
 		}()
-		log.Printf("==== Task [%s] start ====", name)
+		log.Info("Task start.", "task", name)
 		t()
 	})
 }
 
-func checkPreviousTaskStatus(task_id string) bool {
-	status, exist := task_status[task_id]
-	return exist && status
+func init() {
+	c.Start()
+	running.Store(true)
 }
 
-func init() {
+// Scheduler 定时任务调度器组件，可注册到rest.Server，关闭时停止调度，同时作为就绪检查项
+type Scheduler struct{}
+
+// Component 返回定时任务调度器组件，名称为cron
+//
+//	server.Register(task.Component())
+func Component() *Scheduler {
+	return &Scheduler{}
+}
+
+func (s *Scheduler) Name() string {
+	return "cron"
+}
+
+func (s *Scheduler) Start(ctx context.Context) error {
 	c.Start()
+	running.Store(true)
+	return nil
+}
+
+func (s *Scheduler) Stop(ctx context.Context) error {
+	running.Store(false)
+	c.Stop()
+	return nil
+}
+
+// Check 就绪检查，调度器已停止时返回错误
+func (s *Scheduler) Check(ctx context.Context) error {
+	if !running.Load() {
+		return errors.New("cron scheduler stopped")
+	}
+	return nil
 }
@@ -34,3 +34,18 @@ func TestNewTaskFunc(t *testing.T) {
 	}
 	time.Sleep(20 * time.Second)
 }
+
+func TestNewTaskFuncPanic(t *testing.T) {
+	name := "panic_task"
+	NewTaskFunc("* * * * * ?", name, func() {
+		panic("task failed")
+	})
+	// panic后重置运行状态，后续调度不被跳过
+	deadline := time.Now().Add(5 * time.Second)
+	for taskFailures.Value(name) < 2 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if failures, skipped := taskFailures.Value(name), taskSkipped.Value(name); failures < 2 || skipped != 0 {
+		t.Errorf("failures = %v, skipped = %v, want >= 2, 0", failures, skipped)
+	}
+}
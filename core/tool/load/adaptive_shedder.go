@@ -0,0 +1,194 @@
+// 参照https://github.com/zeromicro/go-zero
+package load
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBuckets = 50
+	defaultWindow  = 5 * time.Second
+	// 超过阈值丢弃请求后，1秒内即使CPU回落仍按过载处理，避免抖动
+	coolOffDuration = time.Second
+	// 未采集到响应时间时的默认最小响应时间（毫秒）
+	defaultMinRt = float64(time.Second / time.Millisecond)
+	flyingBeta   = 0.9
+)
+
+// ErrServiceOverloaded 服务过载，请求被丢弃
+var ErrServiceOverloaded = errors.New("service overloaded")
+
+// Shedder 降载器，由AdaptiveShedder实现
+type Shedder interface {
+	// Allow 判断是否放行请求，丢弃时返回错误
+	Allow() (Promise, error)
+}
+
+// AdaptiveShedder 自适应降载：CPU超过阈值且并发请求数超过系统估算的处理能力时丢弃请求。
+// 处理能力 = 窗口内最大QPS * 最小响应时间。
+type AdaptiveShedder struct {
+	cpuThreshold    int64
+	windowScale     int64
+	flying          int64
+	avgFlying       float64
+	avgFlyingLock   sync.RWMutex
+	dropTime        atomic.Int64
+	droppedRecently atomic.Bool
+	passCounter     *rollingWindow
+	rtCounter       *rollingWindow
+	stat            *SheddingStat
+	cpu             func() int64
+}
+
+// Promise 放行的请求结束时必须调用Pass或Fail
+type Promise interface {
+	// Pass 请求处理成功，计入通过数和响应时间
+	Pass()
+	// Fail 请求处理失败，只减少并发数
+	Fail()
+}
+
+// NewAdaptiveShedder cpuThreshold为CPU使用率千分比，如900表示90%
+func NewAdaptiveShedder(cpuThreshold int64) *AdaptiveShedder {
+	bucketDuration := defaultWindow / defaultBuckets
+	return &AdaptiveShedder{
+		cpuThreshold: cpuThreshold,
+		windowScale:  int64(time.Second / bucketDuration),
+		passCounter:  newRollingWindow(defaultBuckets, bucketDuration),
+		rtCounter:    newRollingWindow(defaultBuckets, bucketDuration),
+		stat:         &SheddingStat{},
+		cpu:          CpuUsage,
+	}
+}
+
+// Allow 判断是否放行请求，过载时返回ErrServiceOverloaded
+func (s *AdaptiveShedder) Allow() (Promise, error) {
+	s.stat.IncrementTotal()
+	if s.shouldDrop() {
+		s.dropTime.Store(time.Now().UnixNano())
+		s.droppedRecently.Store(true)
+		s.stat.IncrementDrop()
+		return nil, ErrServiceOverloaded
+	}
+	s.addFlying(1)
+	s.stat.IncrementPass()
+	return &promise{start: time.Now(), shedder: s}, nil
+}
+
+// Stat 降载统计
+func (s *AdaptiveShedder) Stat() *SheddingStat {
+	return s.stat
+}
+
+func (s *AdaptiveShedder) addFlying(delta int64) {
+	flying := atomic.AddInt64(&s.flying, delta)
+	// 请求结束时更新平均并发数，使其平滑变化
+	if delta < 0 {
+		s.avgFlyingLock.Lock()
+		s.avgFlying = s.avgFlying*flyingBeta + float64(flying)*(1-flyingBeta)
+		s.avgFlyingLock.Unlock()
+	}
+}
+
+func (s *AdaptiveShedder) highThru() bool {
+	s.avgFlyingLock.RLock()
+	avgFlying := s.avgFlying
+	s.avgFlyingLock.RUnlock()
+	maxFlight := s.maxFlight()
+	return int64(avgFlying) > maxFlight && atomic.LoadInt64(&s.flying) > maxFlight
+}
+
+func (s *AdaptiveShedder) maxFlight() int64 {
+	// maxQPS * minRt(秒)，至少为1
+	return int64(math.Max(1, float64(s.maxPass()*s.windowScale)*(s.minRt()/1e3)))
+}
+
+func (s *AdaptiveShedder) maxPass() int64 {
+	var result float64 = 1
+	s.passCounter.reduce(func(b *bucket) {
+		if b.sum > result {
+			result = b.sum
+		}
+	})
+	return int64(result)
+}
+
+func (s *AdaptiveShedder) minRt() float64 {
+	result := defaultMinRt
+	s.rtCounter.reduce(func(b *bucket) {
+		if b.count <= 0 {
+			return
+		}
+		avg := math.Round(b.sum / float64(b.count))
+		if avg < result {
+			result = avg
+		}
+	})
+	return result
+}
+
+func (s *AdaptiveShedder) shouldDrop() bool {
+	if s.systemOverloaded() || s.stillHot() {
+		return s.highThru()
+	}
+	return false
+}
+
+func (s *AdaptiveShedder) stillHot() bool {
+	if !s.droppedRecently.Load() {
+		return false
+	}
+	if time.Since(time.Unix(0, s.dropTime.Load())) < coolOffDuration {
+		return true
+	}
+	s.droppedRecently.Store(false)
+	return false
+}
+
+func (s *AdaptiveShedder) systemOverloaded() bool {
+	return s.cpu() >= s.cpuThreshold
+}
+
+type promise struct {
+	start   time.Time
+	shedder *AdaptiveShedder
+}
+
+func (p *promise) Pass() {
+	rt := float64(time.Since(p.start)) / float64(time.Millisecond)
+	p.shedder.addFlying(-1)
+	p.shedder.rtCounter.add(math.Ceil(rt))
+	p.shedder.passCounter.add(1)
+}
+
+func (p *promise) Fail() {
+	p.shedder.addFlying(-1)
+}
+
+// SheddingStat 降载计数，累计值
+type SheddingStat struct {
+	total atomic.Int64
+	pass  atomic.Int64
+	drop  atomic.Int64
+}
+
+func (s *SheddingStat) IncrementTotal() {
+	s.total.Add(1)
+}
+
+func (s *SheddingStat) IncrementPass() {
+	s.pass.Add(1)
+}
+
+func (s *SheddingStat) IncrementDrop() {
+	s.drop.Add(1)
+}
+
+// Loads 返回累计的请求数、放行数和丢弃数
+func (s *SheddingStat) Loads() (total, pass, drop int64) {
+	return s.total.Load(), s.pass.Load(), s.drop.Load()
+}
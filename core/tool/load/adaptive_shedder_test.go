@@ -0,0 +1,46 @@
+package load
+
+import (
+	"testing"
+)
+
+func TestAdaptiveShedder(t *testing.T) {
+	var cpu int64 = 100
+	shedder := NewAdaptiveShedder(800)
+	shedder.cpu = func() int64 { return cpu }
+
+	// CPU未超阈值，全部放行
+	var promises []Promise
+	for i := 0; i < 100; i++ {
+		p, err := shedder.Allow()
+		if err != nil {
+			t.Fatalf("Allow() error = %v under low cpu", err)
+		}
+		promises = append(promises, p)
+	}
+
+	// CPU超阈值且并发远超处理能力时丢弃
+	cpu = 900
+	shedder.avgFlying = 100
+	if _, err := shedder.Allow(); err != ErrServiceOverloaded {
+		t.Fatalf("Allow() error = %v, want ErrServiceOverloaded", err)
+	}
+	// 冷却期内即使CPU回落仍丢弃
+	cpu = 100
+	if _, err := shedder.Allow(); err != ErrServiceOverloaded {
+		t.Fatalf("Allow() error = %v in cool off, want ErrServiceOverloaded", err)
+	}
+
+	// 并发降下来后放行
+	for _, p := range promises {
+		p.Pass()
+	}
+	shedder.avgFlying = 0
+	if _, err := shedder.Allow(); err != nil {
+		t.Fatalf("Allow() error = %v after flying drops", err)
+	}
+	total, pass, drop := shedder.Stat().Loads()
+	if total != 103 || pass != 101 || drop != 2 {
+		t.Errorf("stat = %d/%d/%d, want 103/101/2", total, pass, drop)
+	}
+}
@@ -0,0 +1,130 @@
+// 参照https://github.com/zeromicro/go-zero
+package load
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 采样间隔
+	cpuRefreshInterval = 250 * time.Millisecond
+	// 指数加权平均系数，越大越平滑
+	cpuBeta = 0.95
+)
+
+var (
+	cpuUsage     int64
+	cpuOnce      sync.Once
+	errNoCpuStat = errors.New("cpu stat not available")
+)
+
+// CpuUsage 进程最近的CPU使用率，千分比（相对于可用核数，1000表示占满），非Linux系统恒为0
+func CpuUsage() int64 {
+	startCpuSampler()
+	return atomic.LoadInt64(&cpuUsage)
+}
+
+func startCpuSampler() {
+	cpuOnce.Do(func() {
+		prevProc, prevTotal, err := readCpuTicks()
+		if err != nil {
+			return
+		}
+		cores := availableCores()
+		go func() {
+			ticker := time.NewTicker(cpuRefreshInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				proc, total, err := readCpuTicks()
+				if err != nil || total <= prevTotal {
+					continue
+				}
+				// 进程占整机CPU的比例 * 整机核数 / 可用核数
+				usage := float64(proc-prevProc) / float64(total-prevTotal) * float64(runtime.NumCPU()) / cores * 1000
+				prevProc, prevTotal = proc, total
+				prev := atomic.LoadInt64(&cpuUsage)
+				atomic.StoreInt64(&cpuUsage, int64(float64(prev)*cpuBeta+usage*(1-cpuBeta)))
+			}
+		}()
+	})
+}
+
+// readCpuTicks 读取进程CPU时间（utime+stime）和系统总CPU时间，单位为时钟周期
+func readCpuTicks() (uint64, uint64, error) {
+	procStat, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	// 进程名可能包含空格，从最后一个')'之后解析
+	fields := strings.Fields(string(procStat[strings.LastIndexByte(string(procStat), ')')+1:]))
+	// utime和stime是第14、15个字段，去掉前两个字段后下标为11、12
+	if len(fields) < 13 {
+		return 0, 0, errNoCpuStat
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "cpu" {
+			continue
+		}
+		var total uint64
+		for _, field := range fields[1:] {
+			v, _ := strconv.ParseUint(field, 10, 64)
+			total += v
+		}
+		return utime + stime, total, nil
+	}
+	return 0, 0, errNoCpuStat
+}
+
+// availableCores 可用核数，容器中取cgroup限额
+func availableCores() float64 {
+	cores := float64(runtime.NumCPU())
+	if quota := cgroupCpuQuota(); quota > 0 && quota < cores {
+		return quota
+	}
+	return cores
+}
+
+func cgroupCpuQuota() float64 {
+	// cgroup v2: "<quota> <period>"或"max <period>"
+	if data, err := os.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			quota, _ := strconv.ParseFloat(fields[0], 64)
+			period, _ := strconv.ParseFloat(fields[1], 64)
+			if period > 0 {
+				return quota / period
+			}
+		}
+		return 0
+	}
+	// cgroup v1
+	quotaData, err1 := os.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	periodData, err2 := os.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us")
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	quota, _ := strconv.ParseFloat(strings.TrimSpace(string(quotaData)), 64)
+	period, _ := strconv.ParseFloat(strings.TrimSpace(string(periodData)), 64)
+	if quota <= 0 || period <= 0 {
+		return 0
+	}
+	return quota / period
+}
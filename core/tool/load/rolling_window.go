@@ -0,0 +1,76 @@
+package load
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	sum   float64
+	count int64
+}
+
+func (b *bucket) add(v float64) {
+	b.sum += v
+	b.count++
+}
+
+func (b *bucket) reset() {
+	b.sum = 0
+	b.count = 0
+}
+
+// rollingWindow 按时间分桶的滑动窗口，过期的桶在访问时清零
+type rollingWindow struct {
+	lock     sync.Mutex
+	size     int
+	interval time.Duration
+	buckets  []bucket
+	offset   int
+	lastTime time.Time
+	now      func() time.Time
+}
+
+func newRollingWindow(size int, interval time.Duration) *rollingWindow {
+	return &rollingWindow{
+		size:     size,
+		interval: interval,
+		buckets:  make([]bucket, size),
+		lastTime: time.Now(),
+		now:      time.Now,
+	}
+}
+
+// add 向当前桶添加一个值
+func (rw *rollingWindow) add(v float64) {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+	rw.updateOffset()
+	rw.buckets[rw.offset].add(v)
+}
+
+// reduce 遍历除当前桶外的有效桶，当前桶数据不完整，不参与统计
+func (rw *rollingWindow) reduce(fn func(b *bucket)) {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+	rw.updateOffset()
+	for i := 1; i < rw.size; i++ {
+		fn(&rw.buckets[(rw.offset+i)%rw.size])
+	}
+}
+
+func (rw *rollingWindow) updateOffset() {
+	span := int(rw.now().Sub(rw.lastTime) / rw.interval)
+	if span <= 0 {
+		return
+	}
+	if span > rw.size {
+		span = rw.size
+	}
+	for i := 1; i <= span; i++ {
+		rw.buckets[(rw.offset+i)%rw.size].reset()
+	}
+	rw.offset = (rw.offset + span) % rw.size
+	// 对齐到桶边界
+	rw.lastTime = rw.lastTime.Add(time.Duration(int(rw.now().Sub(rw.lastTime)/rw.interval)) * rw.interval)
+}
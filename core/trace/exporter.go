@@ -0,0 +1,243 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OtlpExporter 以OTLP/HTTP JSON格式导出到collector，如Jaeger、Tempo、OpenTelemetry Collector
+type OtlpExporter struct {
+	// Endpoint collector地址，如http://127.0.0.1:4318，请求路径为/v1/traces
+	Endpoint string
+	Header   http.Header
+	Client   *http.Client
+}
+
+// NewOtlpExporter 创建OTLP/HTTP JSON导出器，headers用于鉴权等，格式为key=value
+func NewOtlpExporter(endpoint string, headers ...string) *OtlpExporter {
+	header := make(http.Header)
+	for _, h := range headers {
+		if k, v, ok := strings.Cut(h, "="); ok {
+			header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+	return &OtlpExporter{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Header:   header,
+		Client:   &http.Client{Timeout: exportTimeout},
+	}
+}
+
+func (e *OtlpExporter) Export(ctx context.Context, spans []*Span) error {
+	data, err := json.Marshal(newOtlpRequest(ServiceName(), spans))
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint+"/v1/traces", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, values := range e.Header {
+		for _, v := range values {
+			request.Header.Add(k, v)
+		}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := e.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %s", response.Status)
+	}
+	return nil
+}
+
+func (e *OtlpExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// WriterExporter 每个span输出一行JSON，用于stdout或文件
+type WriterExporter struct {
+	lock   sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// NewWriterExporter 输出到w，如os.Stdout
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{writer: w}
+}
+
+// NewFileExporter 追加输出到文件，Shutdown时关闭文件
+func NewFileExporter(path string) (*WriterExporter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterExporter{writer: file, closer: file}, nil
+}
+
+type spanRecord struct {
+	TraceID      string         `json:"traceId"`
+	SpanID       string         `json:"spanId"`
+	ParentSpanID string         `json:"parentSpanId,omitempty"`
+	Service      string         `json:"service,omitempty"`
+	Name         string         `json:"name"`
+	Kind         string         `json:"kind"`
+	Start        time.Time      `json:"start"`
+	DurationMs   float64        `json:"durationMs"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+var kindNames = map[int]string{KIND_INTERNAL: "internal", KIND_SERVER: "server", KIND_CLIENT: "client"}
+
+func (e *WriterExporter) Export(ctx context.Context, spans []*Span) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	service := ServiceName()
+	for _, span := range spans {
+		record := spanRecord{
+			TraceID:    span.SpanContext.TraceID.String(),
+			SpanID:     span.SpanContext.SpanID.String(),
+			Service:    service,
+			Name:       span.Name,
+			Kind:       kindNames[span.Kind],
+			Start:      span.StartTime,
+			DurationMs: float64(span.EndTime.Sub(span.StartTime).Microseconds()) / 1000,
+			Attributes: span.Attributes,
+			Error:      span.StatusError,
+		}
+		if span.ParentSpanID != (SpanID{}) {
+			record.ParentSpanID = span.ParentSpanID.String()
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_, err := e.writer.Write(buf.Bytes())
+	return err
+}
+
+func (e *WriterExporter) Shutdown(ctx context.Context) error {
+	if e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
+
+// OTLP JSON结构：https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	// 0未设置 1成功 2失败
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+func newOtlpRequest(service string, spans []*Span) otlpRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.ParentSpanID != (SpanID{}) {
+			s.ParentSpanID = span.ParentSpanID.String()
+		}
+		if span.StatusError != "" {
+			s.Status = otlpStatus{Code: 2, Message: span.StatusError}
+		}
+		otlpSpans = append(otlpSpans, s)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes(map[string]any{"service.name": service})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/kappere/go-rest"}, Spans: otlpSpans}},
+	}}}
+}
+
+// otlpAttributes 按key排序，保证输出稳定
+func otlpAttributes(attributes map[string]any) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		var value map[string]any
+		switch v := attributes[k].(type) {
+		case string:
+			value = map[string]any{"stringValue": v}
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int:
+			value = map[string]any{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": v}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		result = append(result, otlpKeyValue{Key: k, Value: value})
+	}
+	return result
+}
@@ -0,0 +1,393 @@
+// 分布式链路追踪，使用W3C traceparent传播上下文：https://www.w3.org/TR/trace-context/
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const TRACEPARENT_HEADER = "traceparent"
+
+// Span类型，与OTLP的SpanKind一致
+const (
+	KIND_INTERNAL = 1
+	KIND_SERVER   = 2
+	KIND_CLIENT   = 3
+)
+
+const flagSampled = 0x01
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext 跨进程传播的追踪上下文
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&flagSampled != 0
+}
+
+// Traceparent 格式化为traceparent请求头：00-<trace-id>-<span-id>-<flags>
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent 解析traceparent请求头，格式错误或id全为0时返回false
+func ParseTraceparent(s string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, false
+	}
+	// 版本00必须恰好4段，更高版本允许后续扩展字段
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, false
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, false
+	}
+	sc.Flags = flags[0]
+	return sc, sc.IsValid()
+}
+
+// Span 一次操作的耗时和属性，End时交给导出器
+type Span struct {
+	Name         string
+	Kind         int
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]any
+	// StatusError 非空表示失败
+	StatusError string
+
+	lock  sync.Mutex
+	ended bool
+}
+
+// SetAttr 设置属性，值为string、bool、int、int64、float64，其余类型按字符串输出
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]any)
+	}
+	s.Attributes[key] = value
+}
+
+// SetError 标记失败，err为nil时不处理
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.StatusError = err.Error()
+}
+
+// End 结束并导出，未采样的span不导出，重复调用无效
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.lock.Unlock()
+	if s.SpanContext.Sampled() {
+		defaultTracer.export(s)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan 将span放入context，下游通过FromContext获取
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// FromContext 返回context中的当前span，没有时返回nil，nil span的方法均可安全调用
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext 当前trace id，没有时为空
+func TraceIDFromContext(ctx context.Context) string {
+	span := FromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return span.SpanContext.TraceID.String()
+}
+
+// Start 创建子span，ctx中没有span时创建新trace
+func Start(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	var parent SpanContext
+	if span := FromContext(ctx); span != nil {
+		parent = span.SpanContext
+	}
+	return startSpan(ctx, parent, name, kind)
+}
+
+// StartRemote 以上游traceparent为父创建span，traceparent无效时创建新trace
+func StartRemote(ctx context.Context, traceparent string, name string, kind int) (context.Context, *Span) {
+	parent, _ := ParseTraceparent(traceparent)
+	return startSpan(ctx, parent, name, kind)
+}
+
+func startSpan(ctx context.Context, parent SpanContext, name string, kind int) (context.Context, *Span) {
+	span := &Span{
+		Name:      name,
+		Kind:      kind,
+		StartTime: time.Now(),
+	}
+	if parent.IsValid() {
+		span.SpanContext.TraceID = parent.TraceID
+		span.SpanContext.Flags = parent.Flags
+		span.ParentSpanID = parent.SpanID
+	} else {
+		rand.Read(span.SpanContext.TraceID[:])
+		if defaultTracer.sample(span.SpanContext.TraceID) {
+			span.SpanContext.Flags = flagSampled
+		}
+	}
+	rand.Read(span.SpanContext.SpanID[:])
+	return ContextWithSpan(ctx, span), span
+}
+
+// Exporter 导出span，Export在后台goroutine中批量调用
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+	Shutdown(ctx context.Context) error
+}
+
+// 批量导出参数
+const (
+	batchSize     = 512
+	queueSize     = 4096
+	flushInterval = 5 * time.Second
+	exportTimeout = 10 * time.Second
+)
+
+type tracer struct {
+	lock        sync.RWMutex
+	exporter    Exporter
+	serviceName string
+	// 新trace的采样比例，按trace id决定，保证同一trace的采样结果一致
+	sampleRatio float64
+	queue       chan *Span
+	flush       chan chan struct{}
+	stop        chan struct{}
+	done        chan struct{}
+	dropped     atomic.Int64
+}
+
+var defaultTracer = &tracer{sampleRatio: 1}
+
+// SetExporter 设置导出器并启动后台批量导出，exporter为nil时只传播上下文不导出
+func SetExporter(exporter Exporter) {
+	defaultTracer.setExporter(exporter)
+}
+
+// SetServiceName 设置导出的service.name
+func SetServiceName(name string) {
+	defaultTracer.lock.Lock()
+	defer defaultTracer.lock.Unlock()
+	defaultTracer.serviceName = name
+}
+
+// ServiceName 当前service.name
+func ServiceName() string {
+	defaultTracer.lock.RLock()
+	defer defaultTracer.lock.RUnlock()
+	return defaultTracer.serviceName
+}
+
+// SetSampleRatio 设置新trace的采样比例[0, 1]，上游已决定采样的trace沿用上游结果
+func SetSampleRatio(ratio float64) {
+	defaultTracer.lock.Lock()
+	defer defaultTracer.lock.Unlock()
+	defaultTracer.sampleRatio = ratio
+}
+
+// Flush 导出队列中所有span
+func Flush() {
+	defaultTracer.lock.RLock()
+	flush, stopped := defaultTracer.flush, defaultTracer.done
+	defaultTracer.lock.RUnlock()
+	if flush == nil {
+		return
+	}
+	reply := make(chan struct{})
+	select {
+	case flush <- reply:
+		<-reply
+	case <-stopped:
+	}
+}
+
+// Shutdown 导出剩余span并关闭导出器
+func Shutdown(ctx context.Context) error {
+	return defaultTracer.shutdown(ctx)
+}
+
+// Dropped 队列满时丢弃的span数量
+func Dropped() int64 {
+	return defaultTracer.dropped.Load()
+}
+
+func (t *tracer) sample(traceID TraceID) bool {
+	t.lock.RLock()
+	ratio := t.sampleRatio
+	t.lock.RUnlock()
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	return float64(binary.BigEndian.Uint64(traceID[8:])) < ratio*math.MaxUint64
+}
+
+func (t *tracer) setExporter(exporter Exporter) {
+	t.shutdown(context.Background())
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.exporter = exporter
+	if exporter == nil {
+		return
+	}
+	t.queue = make(chan *Span, queueSize)
+	t.flush = make(chan chan struct{})
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go t.run(exporter, t.queue, t.flush, t.stop, t.done)
+}
+
+func (t *tracer) export(span *Span) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if t.queue == nil {
+		return
+	}
+	select {
+	case t.queue <- span:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+func (t *tracer) run(exporter Exporter, queue chan *Span, flush chan chan struct{}, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, batchSize)
+	exportBatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+		if err := exporter.Export(ctx, batch); err != nil {
+			// 导出失败不影响业务
+			slog.Error("Export spans failed.", "count", len(batch), "error", err)
+		}
+		batch = make([]*Span, 0, batchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case span := <-queue:
+				batch = append(batch, span)
+				if len(batch) >= batchSize {
+					exportBatch()
+				}
+			default:
+				exportBatch()
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case span := <-queue:
+			batch = append(batch, span)
+			if len(batch) >= batchSize {
+				exportBatch()
+			}
+		case <-ticker.C:
+			exportBatch()
+		case reply := <-flush:
+			drain()
+			close(reply)
+		case <-stop:
+			drain()
+			return
+		}
+	}
+}
+
+func (t *tracer) shutdown(ctx context.Context) error {
+	t.lock.Lock()
+	exporter, stop, done := t.exporter, t.stop, t.done
+	t.exporter, t.queue, t.flush, t.stop, t.done = nil, nil, nil, nil, nil
+	t.lock.Unlock()
+	if exporter == nil {
+		return nil
+	}
+	close(stop)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return exporter.Shutdown(ctx)
+}
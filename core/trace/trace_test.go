@@ -0,0 +1,201 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true},
+		{"future version with extra field", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"version 00 with extra field", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"short trace id", "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01", false},
+		{"not hex", "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && tt.header[:2] == "00" && sc.Traceparent() != tt.header {
+				t.Errorf("Traceparent() = %s, want %s", sc.Traceparent(), tt.header)
+			}
+		})
+	}
+}
+
+func TestStartPropagation(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx, server := StartRemote(context.Background(), traceparent, "GET /user", KIND_SERVER)
+	_, client := Start(ctx, "RPC order", KIND_CLIENT)
+	if server.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("server span = %+v", server.SpanContext)
+	}
+	if client.SpanContext.TraceID != server.SpanContext.TraceID || client.ParentSpanID != server.SpanContext.SpanID {
+		t.Errorf("client span not child of server span")
+	}
+	if !client.SpanContext.Sampled() {
+		t.Errorf("client span should inherit sampled flag")
+	}
+	if TraceIDFromContext(ctx) != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceIDFromContext = %s", TraceIDFromContext(ctx))
+	}
+
+	// 上游未采样时不采样
+	_, span := StartRemote(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "GET /user", KIND_SERVER)
+	if span.SpanContext.Sampled() {
+		t.Errorf("span should not be sampled")
+	}
+
+	// 新trace按比例采样
+	defer SetSampleRatio(1)
+	SetSampleRatio(0)
+	if _, span := Start(context.Background(), "root", KIND_INTERNAL); span.SpanContext.Sampled() {
+		t.Errorf("span should not be sampled with ratio 0")
+	}
+}
+
+type recordExporter struct {
+	spans []*Span
+}
+
+func (e *recordExporter) Export(ctx context.Context, spans []*Span) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestExportOnEnd(t *testing.T) {
+	exporter := &recordExporter{}
+	SetExporter(exporter)
+	defer SetExporter(nil)
+
+	ctx, parent := Start(context.Background(), "parent", KIND_SERVER)
+	_, child := Start(ctx, "child", KIND_CLIENT)
+	child.SetError(errors.New("timeout"))
+	child.End()
+	child.End()
+	parent.End()
+	Flush()
+
+	if len(exporter.spans) != 2 || exporter.spans[0].Name != "child" || exporter.spans[1].Name != "parent" {
+		t.Fatalf("exported = %v", exporter.spans)
+	}
+	if exporter.spans[0].StatusError != "timeout" {
+		t.Errorf("child error = %q", exporter.spans[0].StatusError)
+	}
+}
+
+func TestOtlpExporter(t *testing.T) {
+	var received otlpRequest
+	var header http.Header
+	// 本地collector替身
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		header = r.Header
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("invalid otlp json: %v", err)
+		}
+	}))
+	defer collector.Close()
+	SetServiceName("test")
+	defer SetServiceName("")
+
+	_, span := StartRemote(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "GET /user", KIND_SERVER)
+	span.SetAttr("http.status_code", 500)
+	span.SetAttr("http.route", "/user")
+	span.SetError(errors.New("Internal Server Error"))
+	span.End()
+
+	exporter := NewOtlpExporter(collector.URL+"/", "Authorization=Bearer abc")
+	if err := exporter.Export(context.Background(), []*Span{span}); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Authorization") != "Bearer abc" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("header = %v", header)
+	}
+	if len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans[0].Spans) != 1 {
+		t.Fatalf("received = %+v", received)
+	}
+	resource := received.ResourceSpans[0].Resource.Attributes
+	if len(resource) != 1 || resource[0].Key != "service.name" || resource[0].Value["stringValue"] != "test" {
+		t.Errorf("resource = %+v", resource)
+	}
+	got := received.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if got.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || got.ParentSpanID != "00f067aa0ba902b7" || got.Kind != KIND_SERVER {
+		t.Errorf("span = %+v", got)
+	}
+	if got.Status.Code != 2 || got.Status.Message != "Internal Server Error" {
+		t.Errorf("status = %+v", got.Status)
+	}
+	if len(got.Attributes) != 2 || got.Attributes[0].Key != "http.route" || got.Attributes[1].Value["intValue"] != "500" {
+		t.Errorf("attributes = %+v", got.Attributes)
+	}
+
+	// collector返回错误
+	failed := NewOtlpExporter(collector.URL + "/unknown")
+	if err := failed.Export(context.Background(), []*Span{span}); err == nil {
+		t.Error("expect error when collector responds 404")
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	_, span := Start(context.Background(), "job", KIND_INTERNAL)
+	span.SetAttr("task", "sync")
+	span.End()
+
+	var buf bytes.Buffer
+	if err := NewWriterExporter(&buf).Export(context.Background(), []*Span{span, span}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	var record spanRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Name != "job" || record.Kind != "internal" || record.Attributes["task"] != "sync" || record.ParentSpanID != "" {
+		t.Errorf("record = %+v", record)
+	}
+
+	path := filepath.Join(t.TempDir(), "trace.log")
+	fileExporter, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileExporter.Export(context.Background(), []*Span{span})
+	fileExporter.Shutdown(context.Background())
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"name":"job"`) {
+		t.Errorf("file content = %s", data)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 与core/config保持一致：ENC(base64(nonce + AES-GCM密文))
+const (
+	configKeyEnv     = "CONFIG_KEY"
+	configKeyFileEnv = "CONFIG_KEY_FILE"
+)
+
+// 生成主密钥
+func cmdGenkey(args []string) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err.Error())
+	}
+	fmt.Println(base64.StdEncoding.EncodeToString(key))
+}
+
+// 加密配置值，明文未通过参数传入时从标准输入读取
+func cmdEncrypt(args []string) {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	keyStr := fs.String("key", "", "base64 master key, default from env "+configKeyEnv+" or "+configKeyFileEnv)
+	fs.Parse(args[2:])
+
+	key, err := masterKey(*keyStr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	plaintext := strings.Join(fs.Args(), " ")
+	if plaintext == "" {
+		reader := bufio.NewReader(os.Stdin)
+		line, _ := reader.ReadString('\n')
+		plaintext = strings.TrimRight(line, "\r\n")
+	}
+	enc, err := encrypt(key, plaintext)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Println(enc)
+}
+
+func masterKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		encoded = os.Getenv(configKeyEnv)
+	}
+	if encoded == "" {
+		keyFile := os.Getenv(configKeyFileEnv)
+		if keyFile == "" {
+			return nil, errors.New("master key not found, use -key or set " + configKeyEnv + " or " + configKeyFileEnv)
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = strings.TrimSpace(string(data))
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func encrypt(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "ENC(" + base64.StdEncoding.EncodeToString(sealed) + ")", nil
+}
@@ -23,6 +23,12 @@ func main() {
 	switch os.Args[1] {
 	case "create":
 		cmdCreate(args)
+	case "genkey":
+		cmdGenkey(args)
+	case "encrypt":
+		cmdEncrypt(args)
+	case "openapi":
+		cmdOpenapi(args)
 	default:
 		help()
 		return
@@ -33,6 +39,12 @@ func help() {
 	const helpStr = `
 Create new project:
 	gotool create <xxxx.com/projectname>
+Generate master key for config encryption:
+	gotool genkey
+Encrypt config value (read from stdin if omitted):
+	gotool encrypt [-key <base64 key>] [plaintext]
+Export OpenAPI document in project directory:
+	gotool openapi [-config etc/<appname>.yaml] [-o openapi.json]
 `
 	fmt.Println(strings.TrimSpace(helpStr))
 }
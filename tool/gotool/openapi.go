@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// 在项目目录下导出OpenAPI文档，由项目main.go的-openapi参数生成，不连接数据库
+func cmdOpenapi(args []string) {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	configFile := fs.String("config", "", "the config file, default etc/<appname>.yaml")
+	output := fs.String("o", "openapi.json", "output file")
+	fs.Parse(args[2:])
+
+	runArgs := []string{"run", "."}
+	if *configFile != "" {
+		runArgs = append(runArgs, "-config", *configFile)
+	}
+	runArgs = append(runArgs, "-openapi", *output)
+	fmt.Println("[exec] go", runArgs)
+	cmd := exec.Command("go", runArgs...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("success: " + *output)
+}
@@ -10,11 +10,11 @@ type Config struct {
 	config.BaseConfig `yaml:",inline"`
 }
 
-func Load(path string) *Config {
+func Load(path string, overrides ...string) *Config {
 	c := Config{
 		BaseConfig: config.DefaultBaseConfig,
 	}
-	err := config.Load(path, &c)
+	err := config.Load(path, &c, overrides...)
 	if err != nil {
 		panic(fmt.Sprintf("Load config file failed! Path: %s, error: %v", path, err))
 	}
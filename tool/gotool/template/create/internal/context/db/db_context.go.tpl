@@ -1,13 +1,12 @@
 package db
 
 import (
-	"log/slog"
-
 	"github.com/go-redis/redis/v8"
 	"{{.fullprojectname}}/internal/config"
 	"{{.fullprojectname}}/internal/model"
 	"github.com/kappere/go-rest/core/db"
 	gorest_redis "github.com/kappere/go-rest/core/redis"
+	"github.com/kappere/go-rest/core/rest"
 	"github.com/kappere/go-rest/core/tool/redislock"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -28,7 +27,7 @@ func NewDbContext(c *config.Config) *DbContext {
 	}
 
 	c.Database.Dialector = mysql.Open(c.Database.Dsn)
-	database := db.NewDatabase(c.Database, c.App.Debug)
+	database := db.NewDatabase(c.Database)
 	redislock.SetStore(redisClient)
 
 	ctx := DbContext{
@@ -41,9 +40,10 @@ func NewDbContext(c *config.Config) *DbContext {
 	return &ctx
 }
 
-func (c *DbContext) Close() {
-	sqlDb, _ := c.Db.DB()
-	sqlDb.Close()
-	c.Redis.Close()
-	slog.Info("DbContext closed.")
+// Components 数据库和redis组件，由Server统一管理启动检查和关闭
+func (c *DbContext) Components() []rest.Component {
+	return []rest.Component{
+		db.NewComponent("db", c.Db),
+		gorest_redis.NewComponent("redis", c.Redis),
+	}
 }
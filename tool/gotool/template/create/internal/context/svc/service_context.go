@@ -1,9 +1,8 @@
 package svc
 
 import (
-	"log/slog"
-
 	"github.com/kappere/go-rest/core/rest"
+	"github.com/kappere/go-rest/core/task"
 	"{{.fullprojectname}}/internal/config"
 	"{{.fullprojectname}}/internal/context/db"
 	"{{.fullprojectname}}/internal/rpc"
@@ -38,10 +37,11 @@ func NewServiceContext(server *rest.Server, c *config.Config) *ServiceContext {
 			{{.Appname}}Service: service.New{{.Appname}}Service(dbContext),
 		},
 		Rpc: Rpc{
-			{{.Appname}}Rpc: rpc.New{{.Appname}}Rpc(),
+			{{.Appname}}Rpc: rpc.New{{.Appname}}Rpc(server.RpcClient()),
 		},
 	}
-	ctx.AddClose(ctx.close)
+	server.Register(dbContext.Components()...)
+	server.Register(task.Component())
 	return &ctx
 }
 
@@ -49,7 +49,3 @@ func (s *ServiceContext) AddClose(f func()) {
 	s.Server.AddClose(f)
 }
 
-func (c *ServiceContext) close() {
-	c.DbContext.Close()
-	slog.Info("ServiceContext closed.")
-}
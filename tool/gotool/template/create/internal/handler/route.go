@@ -1,19 +1,22 @@
 package handler
 
 import (
-	"github.com/gin-gonic/gin"
 	"{{.fullprojectname}}/internal/context/svc"
 	"{{.fullprojectname}}/internal/handler/{{.appname}}"
-	"github.com/kappere/go-rest/core/rpc"
+	"github.com/kappere/go-rest/core/rest"
 )
 
 func RegisterHandlers(ctx *svc.ServiceContext) {
-	engine := ctx.Server.Engine
+	server := ctx.Server
 
-	{{.appname_}}Group := engine.Group("/{{.appname_}}")
-	{{.appname_}}Group.GET("/get", func(c *gin.Context) { {{.appname_}}.Find{{.Appname}}ById(c, ctx) })
-	{{.appname_}}Group.GET("/rget", func(c *gin.Context) { {{.appname_}}.RpcFind{{.Appname}}ById(c, ctx) })
+	// 通过rest.GET、rest.POST等注册到server.Group的路由会出现在/openapi.json中，
+	// 需要鉴权的路由组使用Secure声明，如server.Group("/admin").Secure(rest.SECURITY_JWT, middleware.JwtAuth(nil))
+	{{.appname_}}Group := server.Group("/{{.appname_}}")
+	rest.GET({{.appname_}}Group, "/get", {{.appname_}}.Find{{.Appname}}ById(ctx), rest.Summary("Find {{.appname_}} by id"), rest.ErrorCode({{.appname_}}.ErrNotFound.Code, ""))
+	rest.GET({{.appname_}}Group, "/list", {{.appname_}}.List{{.Appname}}(ctx), rest.Summary("List {{.appname_}} by page"))
+	rest.GET({{.appname_}}Group, "/scan", {{.appname_}}.Scan{{.Appname}}(ctx), rest.Summary("Scan {{.appname_}} by cursor"))
+	rest.GET({{.appname_}}Group, "/rget", {{.appname_}}.RpcFind{{.Appname}}ById(ctx), rest.Summary("Find {{.appname_}} by id through rpc"))
 
-	rpcServer := rpc.Server(engine, ctx.Config.Http.Rpc)
-	rpcServer.POST("/{{.appname_}}/get", func(c *gin.Context) { {{.appname_}}.Find{{.Appname}}ById(c, ctx) })
+	rpcServer := server.RpcGroup()
+	rest.POST(rpcServer, "/{{.appname_}}/get", {{.appname_}}.Find{{.Appname}}ById(ctx))
 }
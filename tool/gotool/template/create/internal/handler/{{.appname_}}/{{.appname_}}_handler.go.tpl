@@ -1,29 +1,53 @@
 package {{.appname_}}
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
-	"github.com/gin-gonic/gin"
 	"{{.fullprojectname}}/internal/context/svc"
+	"{{.fullprojectname}}/internal/model"
 	"github.com/kappere/go-rest/core/httpx"
 )
 
-func Find{{.Appname}}ById(c *gin.Context, ctx *svc.ServiceContext) {
-	id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
-	{{.appname_}} := ctx.Srv.{{.Appname}}Service.Find{{.Appname}}ById(id)
-	if {{.appname_}}.Id == 0 {
-		c.PureJSON(http.StatusOK, httpx.Error("Not found {{.appname_}}!"))
-		return
+// 应用状态码，开启http.httpstatus时返回对应的HTTP状态码
+var ErrNotFound = httpx.RegisterCode(-1001, http.StatusNotFound, "{{.appname_}} not found")
+
+type Find{{.Appname}}ByIdReq struct {
+	Id int64 `form:"id" binding:"required,min=1"`
+}
+
+// List{{.Appname}}Req 分页参数page、size、sort(如-id)和cursor
+type List{{.Appname}}Req struct {
+	httpx.PageRequest
+	Name string `form:"name"`
+}
+
+func Find{{.Appname}}ById(ctx *svc.ServiceContext) func(context.Context, Find{{.Appname}}ByIdReq) (model.{{.Appname}}, error) {
+	return func(c context.Context, req Find{{.Appname}}ByIdReq) (model.{{.Appname}}, error) {
+		{{.appname_}} := ctx.Srv.{{.Appname}}Service.Find{{.Appname}}ById(req.Id)
+		if {{.appname_}}.Id == 0 {
+			return {{.appname_}}, ErrNotFound
+		}
+		return {{.appname_}}, nil
+	}
+}
+
+func List{{.Appname}}(ctx *svc.ServiceContext) func(context.Context, List{{.Appname}}Req) (httpx.PageResult[model.{{.Appname}}], error) {
+	return func(c context.Context, req List{{.Appname}}Req) (httpx.PageResult[model.{{.Appname}}], error) {
+		return ctx.Srv.{{.Appname}}Service.List{{.Appname}}(req.Name, req.PageRequest)
+	}
+}
+
+func Scan{{.Appname}}(ctx *svc.ServiceContext) func(context.Context, httpx.PageRequest) (httpx.PageResult[model.{{.Appname}}], error) {
+	return func(c context.Context, req httpx.PageRequest) (httpx.PageResult[model.{{.Appname}}], error) {
+		return ctx.Srv.{{.Appname}}Service.Scan{{.Appname}}(req)
 	}
-	c.PureJSON(http.StatusOK, httpx.Ok({{.appname_}}))
 }
 
-func RpcFind{{.Appname}}ById(c *gin.Context, ctx *svc.ServiceContext) {
-	{{.appname_}}, err := ctx.Rpc.{{.Appname}}Rpc.Find{{.Appname}}ById(c.Query("id"))
-	if err != nil {
-		c.PureJSON(http.StatusOK, httpx.ErrorWithCode(err.Error(), -1))
-		return
+func RpcFind{{.Appname}}ById(ctx *svc.ServiceContext) func(context.Context, Find{{.Appname}}ByIdReq) (model.{{.Appname}}, error) {
+	// c派生自请求的context，传给RPC调用以传递trace和取消
+	return func(c context.Context, req Find{{.Appname}}ByIdReq) (model.{{.Appname}}, error) {
+		return ctx.Rpc.{{.Appname}}Rpc.Find{{.Appname}}ById(c, strconv.FormatInt(req.Id, 10))
 	}
-	c.PureJSON(http.StatusOK, httpx.Ok({{.appname_}}))
 }
@@ -3,6 +3,8 @@ package model
 import (
 	"strconv"
 
+	"github.com/kappere/go-rest/core/db"
+	"github.com/kappere/go-rest/core/httpx"
 	"gorm.io/gorm"
 )
 
@@ -33,3 +35,20 @@ func (m *{{.Appname}}Model) Get{{.Appname}}ById(id int64) {{.Appname}} {
 	m.db.Model({{.Appname}}{}).Where("id=?", id).Take(&{{.appname_}})
 	return {{.appname_}}
 }
+
+// 允许排序的字段，key为请求中的字段名，value为列名
+var {{.appname_}}Sortable = map[string]string{"id": "id", "name": "name"}
+
+// List{{.Appname}} 分页查询，name非空时按前缀匹配
+func (m *{{.Appname}}Model) List{{.Appname}}(name string, page httpx.PageRequest) (httpx.PageResult[{{.Appname}}], error) {
+	tx := m.db.Model({{.Appname}}{})
+	if name != "" {
+		tx = tx.Where("name LIKE ?", name+"%")
+	}
+	return db.FindPage[{{.Appname}}](tx, page, {{.appname_}}Sortable)
+}
+
+// Scan{{.Appname}} 按id游标分页，用于遍历全表
+func (m *{{.Appname}}Model) Scan{{.Appname}}(page httpx.PageRequest) (httpx.PageResult[{{.Appname}}], error) {
+	return db.FindByCursor[{{.Appname}}](m.db.Model({{.Appname}}{}), page, "id", false)
+}
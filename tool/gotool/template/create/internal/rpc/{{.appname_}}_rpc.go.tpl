@@ -1,24 +1,25 @@
 package rpc
 
 import (
+	"context"
+
 	"{{.fullprojectname}}/internal/model"
 	"github.com/kappere/go-rest/core/rpc"
 )
 
 type {{.Appname}}Rpc struct {
-	rpcService func() rpc.RpcService
+	client *rpc.Client
 }
 
-func New{{.Appname}}Rpc() *{{.Appname}}Rpc {
+func New{{.Appname}}Rpc(client *rpc.Client) *{{.Appname}}Rpc {
 	return &{{.Appname}}Rpc{
-		func() rpc.RpcService {
-			return rpc.Service("{{.appname}}")
-		},
+		client: client,
 	}
 }
 
-func (r *{{.Appname}}Rpc) Find{{.Appname}}ById(id string) (model.{{.Appname}}, error) {
+// Find{{.Appname}}ById ctx为请求的context，向下游传递traceparent，请求取消或超时时中止调用
+func (r *{{.Appname}}Rpc) Find{{.Appname}}ById(ctx context.Context, id string) (model.{{.Appname}}, error) {
 	{{.appname_}} := model.{{.Appname}}{}
-	err := r.rpcService().Call("/{{.appname_}}/get?id="+id, nil).ToObj(&{{.appname_}})
+	err := r.client.Service("{{.appname}}").CallContext(ctx, "/{{.appname_}}/get?id="+id, nil).ToObj(&{{.appname_}})
 	return {{.appname_}}, err
 }
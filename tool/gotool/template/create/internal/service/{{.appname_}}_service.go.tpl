@@ -1,6 +1,7 @@
 package service
 
 import (
+	"github.com/kappere/go-rest/core/httpx"
 	"{{.fullprojectname}}/internal/context/db"
 	"{{.fullprojectname}}/internal/model"
 )
@@ -18,3 +19,11 @@ func New{{.Appname}}Service(dbCtx *db.DbContext) *{{.Appname}}Service {
 func (s *{{.Appname}}Service) Find{{.Appname}}ById(id int64) model.{{.Appname}} {
 	return s.dbCtx.{{.Appname}}Model.Get{{.Appname}}ById(id)
 }
+
+func (s *{{.Appname}}Service) List{{.Appname}}(name string, page httpx.PageRequest) (httpx.PageResult[model.{{.Appname}}], error) {
+	return s.dbCtx.{{.Appname}}Model.List{{.Appname}}(name, page)
+}
+
+func (s *{{.Appname}}Service) Scan{{.Appname}}(page httpx.PageRequest) (httpx.PageResult[model.{{.Appname}}], error) {
+	return s.dbCtx.{{.Appname}}Model.Scan{{.Appname}}(page)
+}
@@ -39,14 +39,38 @@ spec:
         image: {{.appname}}:1.0.0
         ports:
         - containerPort: 80
+        # 存活检查：进程无响应时重启
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 80
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          failureThreshold: 3
+        # 就绪检查：依赖组件不可用或正在关闭时摘除流量
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: 80
+          periodSeconds: 5
+          failureThreshold: 1
         env:
           - name: PROFILE
             value: prod
+          # 解密配置中ENC(...)值的主密钥
+          - name: CONFIG_KEY
+            valueFrom:
+              secretKeyRef:
+                name: {{.appname}}
+                key: CONFIG_KEY
+                optional: true
         volumeMounts:
         - name: config-volume
           mountPath: /etc/{{.appname}}
         - name: log-volume
           mountPath: /var/log/{{.appname}}
+      # 需大于http.shutdowndelay + http.shutdowntimeout
+      terminationGracePeriodSeconds: 30
       volumes:
       - name: config-volume
         configMap:
@@ -2,7 +2,9 @@ package main
 
 import (
 	"flag"
+	"os"
 
+	gorest_config "github.com/kappere/go-rest/core/config"
 	"github.com/kappere/go-rest/core/rest"
 	"{{.fullprojectname}}/internal/config"
 	"{{.fullprojectname}}/internal/context/svc"
@@ -11,13 +13,29 @@ import (
 
 func main() {
 	configFile := flag.String("config", "etc/{{.appname}}.yaml", "the config file")
+	var overrides gorest_config.Overrides
+	flag.Var(&overrides, "set", "override config item, e.g. -set http.port=8080")
+	openapiFile := flag.String("openapi", "", "export the OpenAPI document to file and exit")
 	flag.Parse()
 
-	c := config.Load(*configFile)
+	c := config.Load(*configFile, overrides...)
 
 	server := rest.NewServer(c.BaseConfig)
 	defer server.Close()
 
+	// 离线导出接口文档，只注册路由，不连接数据库
+	if *openapiFile != "" {
+		handler.RegisterHandlers(&svc.ServiceContext{Server: server, Config: c})
+		data, err := server.OpenAPI()
+		if err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(*openapiFile, data, 0644); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	ctx := svc.NewServiceContext(server, c)
 	handler.RegisterHandlers(ctx)
 